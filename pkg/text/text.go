@@ -1,6 +1,9 @@
 package text
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // ShortenMiddle returns 'input' shortened to 'width', by removing characters
 // from the middle of the string. The returned string will have in the middle
@@ -26,3 +29,25 @@ func ShortenMiddle(input string, width int) string {
 
 	return fmt.Sprintf("%s%s%s", input[:left], filler, input[len(input)-right:])
 }
+
+// Wrap returns 'input' with its words separated by newlines instead of spaces
+// as needed to have lines not longer than 'width'. A word longer than 'width'
+// is not split, but stays alone on its line.
+func Wrap(input string, width int) string {
+	var bld strings.Builder
+	lineLen := 0
+	for _, word := range strings.Fields(input) {
+		switch {
+		case lineLen == 0:
+		case lineLen+1+len(word) > width:
+			bld.WriteByte('\n')
+			lineLen = 0
+		default:
+			bld.WriteByte(' ')
+			lineLen++
+		}
+		bld.WriteString(word)
+		lineLen += len(word)
+	}
+	return bld.String()
+}
@@ -109,3 +109,61 @@ func TestTextShortenMiddle(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) { test(t, tc) })
 	}
 }
+
+func TestTextWrap(t *testing.T) {
+	type testCase struct {
+		name  string
+		input string
+		width int
+		want  string
+	}
+
+	testCases := []testCase{
+		{
+			name:  "empty",
+			input: "",
+			width: 10,
+			want:  "",
+		},
+		{
+			name:  "fits",
+			input: "peel the mango",
+			width: 20,
+			want:  "peel the mango",
+		},
+		{
+			name:  "exactly fits",
+			input: "peel the mango",
+			width: 14,
+			want:  "peel the mango",
+		},
+		{
+			name:  "wraps",
+			input: "peel the mango and slice it",
+			width: 10,
+			want:  "peel the\nmango and\nslice it",
+		},
+		{
+			name:  "long word",
+			input: "a supercalifragilistic mango",
+			width: 10,
+			want:  "a\nsupercalifragilistic\nmango",
+		},
+		{
+			name:  "collapses spaces",
+			input: "  peel   the\tmango ",
+			width: 20,
+			want:  "peel the mango",
+		},
+	}
+
+	test := func(t *testing.T, tc testCase) {
+		have := text.Wrap(tc.input, tc.width)
+
+		rosina.AssertEqual(t, have, tc.want, "Wrap")
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) { test(t, tc) })
+	}
+}
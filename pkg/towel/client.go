@@ -0,0 +1,246 @@
+package towel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Client is a client of the Jira REST API. Create it with NewClient.
+// A Client is safe for concurrent use.
+type Client struct {
+	serverURL  string // For example: https://x.atlassian.net
+	baseURL    string // For example: https://x.atlassian.net/rest/api/2
+	email      string
+	apiToken   string
+	httpClient *http.Client
+	progress   io.Writer
+}
+
+// Option configures a Client. See the With* functions.
+type Option func(*Client)
+
+// WithHTTPClient makes the Client use 'hclient' instead of a default
+// http.Client.
+func WithHTTPClient(hclient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = hclient
+	}
+}
+
+// WithProgress makes the Client report the progress of long operations, such
+// as paginated searches, to 'w'. By default, no progress is reported.
+func WithProgress(w io.Writer) Option {
+	return func(c *Client) {
+		c.progress = w
+	}
+}
+
+// NewClient returns a Client for the Jira instance and credentials in 'config'.
+func NewClient(config Config, opts ...Option) *Client {
+	// It seems that the only difference between v2 and v3 is that v2
+	// returns a plain text "Description" field, while v3 returns a
+	// Jira-specific sort of rich text format.
+	// For what we want to do, plain text is preferable.
+	serverURL := "https://" + config.Server
+	client := &Client{
+		serverURL: serverURL,
+		//baseURL:  serverURL + "/rest/api/3",
+		baseURL:    serverURL + "/rest/api/2",
+		email:      config.Email,
+		apiToken:   config.ApiToken,
+		httpClient: &http.Client{},
+		progress:   io.Discard,
+	}
+	for _, opt := range opts {
+		opt(client)
+	}
+	return client
+}
+
+// BrowseURL returns the URL of the web page of the issue with key 'key'.
+func (c *Client) BrowseURL(key string) string {
+	return c.serverURL + "/browse/" + url.PathEscape(key)
+}
+
+// SearchPage is one page of the reply to a JQL search.
+type SearchPage struct {
+	StartAt    int
+	MaxResults int
+	Total      int
+	// Raw is the JSON reply, as sent by Jira.
+	Raw []byte
+}
+
+// Search returns an iterator over the issues matching 'jql'. The issues are
+// fetched and decoded one page at a time, so that memory usage does not depend
+// on the total number of issues. The iteration stops at the first error.
+func (c *Client) Search(ctx context.Context, jql string) iter.Seq2[Issue, error] {
+	return c.searchIssues(ctx, queryRequest{
+		JQL:        jql,
+		MaxResults: 1_000,
+		StartAt:    0,
+	})
+}
+
+// GetIssues returns an iterator over the issues with 'keys'. The issues are
+// fetched in batches, with one search per batch. Keys of issues that do not
+// exist, or that the user cannot see, are silently skipped. The iteration
+// stops at the first error.
+func (c *Client) GetIssues(ctx context.Context, keys []string) iter.Seq2[Issue, error] {
+	const batchSize = 100
+
+	return func(yield func(Issue, error) bool) {
+		for batch := range slices.Chunk(keys, batchSize) {
+			req := queryRequest{
+				JQL:           fmt.Sprintf("key in (%s)", strings.Join(batch, ", ")),
+				MaxResults:    batchSize,
+				StartAt:       0,
+				ValidateQuery: "warn",
+			}
+			for ticket, err := range c.searchIssues(ctx, req) {
+				if !yield(ticket, err) || err != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (c *Client) searchIssues(ctx context.Context, req queryRequest) iter.Seq2[Issue, error] {
+	return func(yield func(Issue, error) bool) {
+		for page, err := range c.searchPages(ctx, req) {
+			if err != nil {
+				yield(Issue{}, err)
+				return
+			}
+			issues, err := decodePage(page.Raw)
+			if err != nil {
+				yield(Issue{}, fmt.Errorf("page at %d: %s", page.StartAt, err))
+				return
+			}
+			for _, ticket := range issues {
+				if !yield(ticket, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// SearchPages returns an iterator over the pages of the reply to 'jql', as
+// sent by Jira. Each page is fetched only when the previous one has been
+// consumed. The iteration stops at the first error.
+func (c *Client) SearchPages(ctx context.Context, jql string) iter.Seq2[SearchPage, error] {
+	return c.searchPages(ctx, queryRequest{
+		JQL:        jql,
+		MaxResults: 1_000,
+		StartAt:    0,
+	})
+}
+
+func (c *Client) searchPages(ctx context.Context, req queryRequest) iter.Seq2[SearchPage, error] {
+	endpoint := c.baseURL + "/search"
+
+	return func(yield func(SearchPage, error) bool) {
+		defer fmt.Fprintln(c.progress)
+		// The "range 500" is here only as a safety net to avoid infinite loops.
+		for range 500 {
+			reqBody, err := json.Marshal(req)
+			if err != nil {
+				yield(SearchPage{}, fmt.Errorf("query: %s", err))
+				return
+			}
+			reply, err := post(ctx, c.httpClient, c.email, c.apiToken,
+				endpoint, bytes.NewReader(reqBody))
+			if err != nil {
+				yield(SearchPage{}, err)
+				return
+			}
+
+			var pagination pagination
+			if err := json.Unmarshal(reply, &pagination); err != nil {
+				yield(SearchPage{}, err)
+				return
+			}
+			page := SearchPage{
+				StartAt:    pagination.StartAt,
+				MaxResults: pagination.MaxResults,
+				Total:      pagination.Total,
+				Raw:        reply,
+			}
+			if !yield(page, nil) {
+				return
+			}
+
+			req.StartAt += page.MaxResults
+			if req.StartAt >= page.Total {
+				return
+			}
+			fmt.Fprint(c.progress, req.StartAt, " ")
+		}
+	}
+}
+
+// GetIssue returns the issue with key 'key', for example "MANGO-42".
+func (c *Client) GetIssue(ctx context.Context, key string) (Issue, error) {
+	reply, err := get(ctx, c.httpClient, c.email, c.apiToken,
+		c.baseURL+"/issue/"+url.PathEscape(key))
+	if err != nil {
+		return Issue{}, fmt.Errorf("get issue %s: %s", key, err)
+	}
+	var ticket Issue
+	if err := json.Unmarshal(reply, &ticket); err != nil {
+		return Issue{}, fmt.Errorf("get issue %s: JSON: %s", key, err)
+	}
+	return ticket, nil
+}
+
+// Field describes a field, system or custom, of the Jira instance.
+type Field struct {
+	ID     string `json:"id"` // For example "summary" or "customfield_11919".
+	Name   string `json:"name"`
+	Custom bool   `json:"custom"`
+	Schema struct {
+		Type     string `json:"type"`
+		Custom   string `json:"custom"`
+		CustomID int    `json:"customId"`
+	} `json:"schema"`
+}
+
+// Fields returns all the fields, system and custom, of the Jira instance. This
+// is the way to discover the ID of a custom field from its name.
+func (c *Client) Fields(ctx context.Context) ([]Field, error) {
+	reply, err := get(ctx, c.httpClient, c.email, c.apiToken, c.baseURL+"/field")
+	if err != nil {
+		return nil, fmt.Errorf("fields: %s", err)
+	}
+	var fields []Field
+	if err := json.Unmarshal(reply, &fields); err != nil {
+		return nil, fmt.Errorf("fields: JSON: %s", err)
+	}
+	return fields, nil
+}
+
+// LinkTypes returns the issue link types of the Jira instance.
+func (c *Client) LinkTypes(ctx context.Context) ([]LinkType, error) {
+	reply, err := get(ctx, c.httpClient, c.email, c.apiToken,
+		c.baseURL+"/issueLinkType")
+	if err != nil {
+		return nil, fmt.Errorf("link types: %s", err)
+	}
+	var resp struct {
+		IssueLinkTypes []LinkType `json:"issueLinkTypes"`
+	}
+	if err := json.Unmarshal(reply, &resp); err != nil {
+		return nil, fmt.Errorf("link types: JSON: %s", err)
+	}
+	return resp.IssueLinkTypes, nil
+}
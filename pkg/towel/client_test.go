@@ -0,0 +1,239 @@
+package towel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/marco-m/rosina"
+)
+
+// newFakeJira returns a client connected to a fake Jira server. The server
+// replies to searches with 'total' synthetic issues, in pages of at most
+// 'pageSize' issues (like the real Jira, it ignores a bigger maxResults).
+func newFakeJira(t testing.TB, total int, pageSize int) *Client {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /rest/api/2/search",
+		func(w http.ResponseWriter, r *http.Request) {
+			var req queryRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, fakeSearchPage(req.StartAt, pageSize, total))
+		})
+	mux.HandleFunc("GET /rest/api/2/issue/{key}",
+		func(w http.ResponseWriter, r *http.Request) {
+			var num int
+			if _, err := fmt.Sscanf(r.PathValue("key"), "MANGO-%d", &num); err != nil ||
+				num < 1 || num > total {
+				http.Error(w, `{"errorMessages":["Issue does not exist"]}`,
+					http.StatusNotFound)
+				return
+			}
+			fmt.Fprint(w, fakeIssue(num))
+		})
+	mux.HandleFunc("GET /rest/api/2/field",
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `[
+  {"id": "summary", "name": "Summary", "custom": false, "schema": {"type": "string"}},
+  {"id": "customfield_11919", "name": "My Product", "custom": true,
+   "schema": {"type": "option", "custom": "com.atlassian.jira.plugin.system.customfieldtypes:select", "customId": 11919}}
+]`)
+		})
+	mux.HandleFunc("GET /rest/api/2/issueLinkType",
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"issueLinkTypes": [
+  {"id": "10000", "name": "Blocks", "inward": "is blocked by", "outward": "blocks"},
+  {"id": "10003", "name": "Relates", "inward": "relates to", "outward": "relates to"}
+]}`)
+		})
+
+	srv := httptest.NewTLSServer(mux)
+	t.Cleanup(srv.Close)
+
+	config := Config{
+		Version: 1,
+		Server:  strings.TrimPrefix(srv.URL, "https://"),
+	}
+	return NewClient(config, WithHTTPClient(srv.Client()))
+}
+
+func fakeSearchPage(startAt, pageSize, total int) string {
+	var bld strings.Builder
+	fmt.Fprintf(&bld, `{"startAt":%d,"maxResults":%d,"total":%d,"issues":[`,
+		startAt, pageSize, total)
+	for i := startAt; i < min(startAt+pageSize, total); i++ {
+		if i > startAt {
+			bld.WriteString(",")
+		}
+		bld.WriteString(fakeIssue(i + 1))
+	}
+	bld.WriteString("]}")
+	return bld.String()
+}
+
+func fakeIssue(num int) string {
+	return fmt.Sprintf(`{"key":"MANGO-%d","fields":{`+
+		`"summary":"Issue number %d","status":{"name":"To Do"},`+
+		`"issuetype":{"name":"Story"},"customfield_11919":{"value":"Juicy"},`+
+		`"issuelinks":[{"type":{"name":"Blocks","inward":"is blocked by","outward":"blocks"},`+
+		`"outwardIssue":{"key":"MANGO-%d"}}]}}`,
+		num, num, num+1)
+}
+
+func TestSearchFollowsPagination(t *testing.T) {
+	client := newFakeJira(t, 7, 3)
+
+	var keys []string
+	for ticket, err := range client.Search(context.Background(), "project = MANGO") {
+		rosina.AssertNoError(t, err)
+		keys = append(keys, ticket.Key)
+	}
+
+	rosina.AssertEqual(t, strings.Join(keys, " "),
+		"MANGO-1 MANGO-2 MANGO-3 MANGO-4 MANGO-5 MANGO-6 MANGO-7", "keys")
+}
+
+func TestSearchStopsEarly(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			var req queryRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			fmt.Fprint(w, fakeSearchPage(req.StartAt, 3, 100))
+		}))
+	t.Cleanup(srv.Close)
+	config := Config{Server: strings.TrimPrefix(srv.URL, "https://")}
+	client := NewClient(config, WithHTTPClient(srv.Client()))
+
+	for ticket, err := range client.Search(context.Background(), "project = MANGO") {
+		rosina.AssertNoError(t, err)
+		if ticket.Key == "MANGO-2" {
+			break
+		}
+	}
+
+	rosina.AssertEqual(t, requests.Load(), 1, "HTTP requests")
+}
+
+func TestSearchReportsErrors(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, `{"errorMessages":["bad JQL"]}`, http.StatusBadRequest)
+		}))
+	t.Cleanup(srv.Close)
+	config := Config{Server: strings.TrimPrefix(srv.URL, "https://")}
+	client := NewClient(config, WithHTTPClient(srv.Client()))
+
+	var errs []error
+	for _, err := range client.Search(context.Background(), "project = ???") {
+		errs = append(errs, err)
+	}
+
+	rosina.AssertEqual(t, len(errs), 1, "number of errors")
+	rosina.AssertEqual(t, strings.Contains(errs[0].Error(), "StatusCode: 400"),
+		true, "error mentions the status code")
+}
+
+func TestGetIssue(t *testing.T) {
+	client := newFakeJira(t, 7, 3)
+
+	ticket, err := client.GetIssue(context.Background(), "MANGO-5")
+	rosina.AssertNoError(t, err)
+
+	rosina.AssertEqual(t, ticket.Key, "MANGO-5", "key")
+	rosina.AssertEqual(t, ticket.Fields.Summary, "Issue number 5", "summary")
+	rosina.AssertEqual(t, ticket.Fields.CustomFields["customfield_11919"].Value(),
+		"Juicy", "custom field")
+}
+
+func TestGetIssueNotFound(t *testing.T) {
+	client := newFakeJira(t, 7, 3)
+
+	_, err := client.GetIssue(context.Background(), "MANGO-99")
+
+	rosina.AssertEqual(t, err != nil, true, "error")
+	rosina.AssertEqual(t, strings.Contains(err.Error(), "StatusCode: 404"), true,
+		"error mentions the status code")
+}
+
+func TestFields(t *testing.T) {
+	client := newFakeJira(t, 7, 3)
+
+	fields, err := client.Fields(context.Background())
+	rosina.AssertNoError(t, err)
+
+	rosina.AssertEqual(t, len(fields), 2, "number of fields")
+	rosina.AssertEqual(t, fields[1].Name, "My Product", "name")
+	rosina.AssertEqual(t, fields[1].Custom, true, "custom")
+	rosina.AssertEqual(t, fields[1].Schema.CustomID, 11919, "custom ID")
+}
+
+func TestLinkTypes(t *testing.T) {
+	client := newFakeJira(t, 7, 3)
+
+	linkTypes, err := client.LinkTypes(context.Background())
+	rosina.AssertNoError(t, err)
+
+	rosina.AssertEqual(t, len(linkTypes), 2, "number of link types")
+	rosina.AssertEqual(t, linkTypes[0].Name, "Blocks", "name")
+	rosina.AssertEqual(t, linkTypes[0].Inward, "is blocked by", "inward")
+	rosina.AssertEqual(t, linkTypes[0].Outward, "blocks", "outward")
+}
+
+// BenchmarkSearch shows that the memory used while iterating over the
+// issues stays flat as the number of issues grows: metric peak-heap-B should
+// be roughly the same for all the sizes.
+func BenchmarkSearch(b *testing.B) {
+	const pageSize = 100
+
+	for _, total := range []int{1_000, 10_000, 50_000} {
+		b.Run(fmt.Sprintf("issues=%d", total), func(b *testing.B) {
+			client := newFakeJira(b, total, pageSize)
+			b.ReportAllocs()
+
+			var peak uint64
+			for range b.N {
+				runtime.GC()
+				var stats runtime.MemStats
+				runtime.ReadMemStats(&stats)
+				baseline := stats.HeapAlloc
+
+				count := 0
+				for _, err := range client.Search(context.Background(),
+					"project = MANGO") {
+					if err != nil {
+						b.Fatal(err)
+					}
+					count++
+					if count%pageSize == 0 {
+						runtime.ReadMemStats(&stats)
+						if stats.HeapAlloc > baseline {
+							peak = max(peak, stats.HeapAlloc-baseline)
+						}
+					}
+				}
+				if count != total {
+					b.Fatalf("have: %d issues; want: %d", count, total)
+				}
+			}
+			b.ReportMetric(float64(peak), "peak-heap-B")
+		})
+	}
+}
+
+func TestBrowseURL(t *testing.T) {
+	client := NewClient(Config{Server: "x.atlassian.net"})
+
+	rosina.AssertEqual(t, client.BrowseURL("MANGO-42"),
+		"https://x.atlassian.net/browse/MANGO-42", "browse URL")
+}
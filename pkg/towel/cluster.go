@@ -0,0 +1,186 @@
+package towel
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// clusterFields are the built-in fields that can be used to cluster, in
+// addition to the custom fields of --custom-fields. Field "sprint" is a
+// custom field, but it can be discovered; see sprintFieldID.
+var clusterFields = []string{"project", "assignee", "status", "component",
+	"fixVersion", "sprint", "label"}
+
+// clusterValues returns the values of field 'field' of 'ticket', either a
+// built-in field (see clusterFields) or a custom field in 'lut'.
+func clusterValues(ticket Issue, field string, lut map[string]int) []string {
+	fields := ticket.Fields
+	nonEmpty := func(values ...string) []string {
+		return slices.DeleteFunc(values, func(v string) bool { return v == "" })
+	}
+	switch field {
+	case "project":
+		return nonEmpty(fields.Project.Name)
+	case "assignee":
+		if fields.Assignee == nil {
+			return nil
+		}
+		return nonEmpty(fields.Assignee.DisplayName)
+	case "status":
+		return nonEmpty(fields.Status.Name)
+	case "component":
+		var values []string
+		for _, component := range fields.Components {
+			values = append(values, component.Name)
+		}
+		return nonEmpty(values...)
+	case "fixVersion":
+		var values []string
+		for _, version := range fields.FixVersions {
+			values = append(values, version.Name)
+		}
+		return nonEmpty(values...)
+	case "label":
+		return nonEmpty(fields.Labels...)
+	default:
+		return lookupCustomField(fields.CustomFields, lut, field).Values()
+	}
+}
+
+// checkClusterBy returns an error if a field of 'clusterBy' is neither a
+// built-in field nor a custom field in 'lut'.
+func checkClusterBy(clusterBy []string, lut map[string]int) error {
+	for _, field := range clusterBy {
+		if _, found := lut[field]; found {
+			continue
+		}
+		if field == "sprint" || !slices.Contains(clusterFields, field) {
+			return fmt.Errorf("cluster-by: %q: neither one of %s nor in --custom-fields",
+				field, strings.Join(clusterFields, ", "))
+		}
+	}
+	return nil
+}
+
+// sprintFieldID returns the ID of the custom field holding the sprints, by
+// looking for the custom field type of Jira Software.
+func sprintFieldID(ctx context.Context, client *Client) (int, error) {
+	fields, err := client.Fields(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, field := range fields {
+		if field.Schema.Custom == "com.pyxis.greenhopper.jira:gh-sprint" {
+			return field.Schema.CustomID, nil
+		}
+	}
+	return 0, fmt.Errorf("sprint: custom field not found")
+}
+
+// clusterPaths returns the paths of the nested clusters where 'ticket' goes,
+// one level per field of 'clusterBy'. An empty field gives the cluster
+// "unknown". A multi-valued field gives only its first value, unless
+// 'duplicate', in which case it gives one path per value (the ticket is then
+// drawn once per path).
+func clusterPaths(ticket Issue, clusterBy []string, lut map[string]int, duplicate bool,
+) [][]string {
+	paths := [][]string{nil}
+	for _, field := range clusterBy {
+		values := clusterValues(ticket, field, lut)
+		if len(values) == 0 {
+			values = []string{"unknown"}
+		}
+		if !duplicate {
+			values = values[:1]
+		}
+		var next [][]string
+		for _, path := range paths {
+			for _, value := range values {
+				next = append(next, append(slices.Clip(path), value))
+			}
+		}
+		paths = next
+	}
+	return paths
+}
+
+// clusterTree is a cluster of nodes, with nested clusters.
+type clusterTree struct {
+	name     string
+	nodes    []string // IDs of the DOT nodes.
+	children []*clusterTree
+}
+
+// add adds the DOT node 'id' to the nested cluster at 'path'.
+func (c *clusterTree) add(path []string, id string) {
+	if len(path) == 0 {
+		c.nodes = append(c.nodes, id)
+		return
+	}
+	idx := slices.IndexFunc(c.children, func(child *clusterTree) bool {
+		return child.name == path[0]
+	})
+	if idx == -1 {
+		c.children = append(c.children, &clusterTree{name: path[0]})
+		idx = len(c.children) - 1
+	}
+	c.children[idx].add(path[1:], id)
+}
+
+//	subgraph cluster_0 {
+//		label = "process #1";
+//		style=filled;
+//		color=lightgrey;
+//		node [style=filled,color=white];
+//		a0 -> a1 -> a2 -> a3;
+//	}
+func (c *clusterTree) write(bld *strings.Builder, indent string) {
+	invisible := 0
+	var write func(cluster *clusterTree, id string, depth int)
+	write = func(cluster *clusterTree, id string, depth int) {
+		prefix := strings.Repeat(indent, depth+1)
+
+		// hack graphviz bug. Invisible cluster
+		// https://forum.graphviz.org/t/how-to-add-space-between-clusters/1209
+		invisible++
+		fmt.Fprintf(bld, "%ssubgraph cluster_wrap_%d {\n", prefix, invisible)
+		fmt.Fprintf(bld, "%scolor=%q\n", prefix, "white")
+
+		fmt.Fprintf(bld, "%ssubgraph %q {\n", prefix, "cluster_"+id)
+		// sigh this is internal margin!
+		// fmt.Fprintf(&bld, "margin=55\n")
+		color := clusterColors[min(depth, len(clusterColors)-1)]
+		fmt.Fprintf(bld, "%s%slabel=%q style=filled color=%q\n", prefix, indent,
+			cluster.name, color)
+		for _, node := range cluster.nodes {
+			fmt.Fprintf(bld, "%s%s%q\n", prefix, indent, node)
+		}
+		for _, child := range cluster.sortedChildren() {
+			write(child, id+"/"+child.name, depth+1)
+		}
+		fmt.Fprintf(bld, "%s}\n", prefix)
+
+		// close wrap cluster hack see above
+		fmt.Fprintf(bld, "%s}\n", prefix)
+	}
+	for _, child := range c.sortedChildren() {
+		write(child, child.name, 0)
+	}
+}
+
+// sortedChildren returns the nested clusters sorted by name, with cluster
+// "unknown" last.
+func (c *clusterTree) sortedChildren() []*clusterTree {
+	last := func(cluster *clusterTree) int {
+		if cluster.name == "unknown" {
+			return 1
+		}
+		return 0
+	}
+	return slices.SortedFunc(slices.Values(c.children), func(a, b *clusterTree) int {
+		return cmp.Or(cmp.Compare(last(a), last(b)), cmp.Compare(a.name, b.name))
+	})
+}
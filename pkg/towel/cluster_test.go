@@ -0,0 +1,129 @@
+package towel
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/marco-m/rosina"
+)
+
+func TestClusterPaths(t *testing.T) {
+	type testCase struct {
+		name      string
+		clusterBy []string
+		duplicate bool
+		want      string
+	}
+
+	ticket := newIssue("A-1", "To Do")
+	ticket.Fields.Project = Project{Key: "A", Name: "Apple"}
+	ticket.Fields.Labels = []string{"red", "green"}
+	ticket.Fields.Components = []Component{{Name: "core"}}
+	ticket.Fields.CustomFields = map[string]CustomField{
+		"customfield_10020": CustomField(json.RawMessage(
+			`[{"id": 1, "name": "Sprint 1"}, {"id": 2, "name": "Sprint 2"}]`)),
+		"customfield_11919": CustomField(json.RawMessage(`{"value": "towel"}`)),
+	}
+	lut := map[string]int{"sprint": 10020, "product": 11919}
+
+	testCases := []testCase{
+		{name: "no clusters", want: "[[]]"},
+		{name: "built-in field", clusterBy: []string{"project"}, want: "[[Apple]]"},
+		{name: "custom field", clusterBy: []string{"product"}, want: "[[towel]]"},
+		{name: "empty field", clusterBy: []string{"assignee"}, want: "[[unknown]]"},
+		{
+			name:      "nested",
+			clusterBy: []string{"product", "component", "status"},
+			want:      "[[towel core To Do]]",
+		},
+		{
+			name:      "multi-valued, first value",
+			clusterBy: []string{"label", "sprint"},
+			want:      "[[red Sprint 1]]",
+		},
+		{
+			name:      "multi-valued, duplicate",
+			clusterBy: []string{"label", "sprint"},
+			duplicate: true,
+			want:      "[[red Sprint 1] [red Sprint 2] [green Sprint 1] [green Sprint 2]]",
+		},
+	}
+
+	test := func(t *testing.T, tc testCase) {
+		have := clusterPaths(ticket, tc.clusterBy, lut, tc.duplicate)
+
+		rosina.AssertEqual(t, fmt.Sprint(have), tc.want, "paths")
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) { test(t, tc) })
+	}
+}
+
+func TestCheckClusterBy(t *testing.T) {
+	lut := map[string]int{"product": 11919}
+
+	rosina.AssertNoError(t, checkClusterBy([]string{"product", "fixVersion"}, lut))
+
+	err := checkClusterBy([]string{"banana"}, lut)
+	rosina.AssertEqual(t, err != nil, true, "unknown field")
+	err = checkClusterBy([]string{"sprint"}, lut)
+	rosina.AssertEqual(t, err != nil, true, "sprint without custom field")
+}
+
+func TestDotRendererNestedClusters(t *testing.T) {
+	withLabels := func(ticket Issue, labels ...string) Issue {
+		ticket.Fields.Project = Project{Name: "Apple"}
+		ticket.Fields.Labels = labels
+		return ticket
+	}
+	g := NewGraph()
+	g.AddIssue(withLabels(newIssue("A-1", "To Do", "A-2"), "red", "green"))
+	g.AddIssue(withLabels(newIssue("A-2", "To Do"), "red"))
+
+	var bld strings.Builder
+	err := dotRenderer{
+		rankdir:          "LR",
+		clusterBy:        []string{"project", "label"},
+		clusterDuplicate: true,
+	}.Render(&bld, g)
+	rosina.AssertNoError(t, err)
+
+	dot := bld.String()
+	for _, want := range []string{
+		`"A-1 (1)" [label="summary of A-1\nA-1 To Do" fillcolor="cadetblue1"]`,
+		`"A-1 (2)" [label="summary of A-1\nA-1 To Do" fillcolor="cadetblue1"]`,
+		`"A-1 (1)" -> "A-2" [label="blocks" color="red"]`,
+		`"A-1 (2)" -> "A-2" [label="blocks" color="red"]`,
+	} {
+		rosina.AssertEqual(t, strings.Contains(dot, want), true, want)
+	}
+
+	clusters := dot[strings.Index(dot, `    subgraph`):]
+	want := `    subgraph cluster_wrap_1 {
+    color="white"
+    subgraph "cluster_Apple" {
+        label="Apple" style=filled color="aquamarine"
+        subgraph cluster_wrap_2 {
+        color="white"
+        subgraph "cluster_Apple/green" {
+            label="green" style=filled color="paleturquoise1"
+            "A-1 (2)"
+        }
+        }
+        subgraph cluster_wrap_3 {
+        color="white"
+        subgraph "cluster_Apple/red" {
+            label="red" style=filled color="paleturquoise1"
+            "A-1 (1)"
+            "A-2"
+        }
+        }
+    }
+    }
+}
+`
+	rosina.AssertEqual(t, clusters, want, "clusters")
+}
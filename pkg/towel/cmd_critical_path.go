@@ -0,0 +1,67 @@
+package towel
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/marco-m/clim"
+)
+
+type criticalPathCmd struct {
+	JQL          string
+	LinkTypes    []string
+	CustomFields []string
+}
+
+func newCriticalPathCLI() *clim.CLI[App] {
+	criticalPathCmd := criticalPathCmd{}
+
+	cli := clim.New("critical-path",
+		"find the longest chain of dependencies among the unfinished tickets",
+		criticalPathCmd.Run)
+
+	cli.AddFlag(&clim.Flag{
+		Value: clim.String(&criticalPathCmd.JQL, ""),
+		Long:  "jql", Label: "QUERY",
+		Help:     "JQL query, for example: 'project = \"MY PROJECT\"''. An empty string is not accepted because it would query ALL the projects in the Jira instance",
+		Required: true,
+	})
+	cli.AddFlag(&clim.Flag{
+		Value: clim.StringSlice(&criticalPathCmd.LinkTypes, dependencyLinkTypes),
+		Long:  "link-types", Label: "type[,type,..]",
+		Help: "Link types that form a dependency",
+	})
+	cli.AddFlag(&clim.Flag{
+		Value: clim.StringSlice(&criticalPathCmd.CustomFields, nil),
+		Long:  "custom-fields", Label: "name:id[,name:id,..]",
+		Help: "List of customfield names to IDs. Name 'points' is the story points (eg: points:10016)",
+	})
+
+	return cli
+}
+
+func (cmd *criticalPathCmd) Run(app App) error {
+	lut, err := parseCustomFields(cmd.CustomFields)
+	if err != nil {
+		return err
+	}
+
+	client, err := app.newClient()
+	if err != nil {
+		return fmt.Errorf("critical-path: %w", err)
+	}
+
+	g, err := searchGraph(context.Background(), client, cmd.JQL)
+	if err != nil {
+		return fmt.Errorf("critical-path: %s", err)
+	}
+
+	wgt := chooseWeight(g.Issues(), lut)
+	path, total, err := findCriticalPath(g, cmd.LinkTypes, wgt)
+	if err != nil {
+		return fmt.Errorf("critical-path: %s", err)
+	}
+	printCriticalPath(os.Stdout, g, path, total, wgt)
+	return nil
+}
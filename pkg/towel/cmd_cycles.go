@@ -0,0 +1,59 @@
+package towel
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/marco-m/clim"
+)
+
+type cyclesCmd struct {
+	JQL       string
+	LinkTypes []string
+}
+
+func newCyclesCLI() *clim.CLI[App] {
+	cyclesCmd := cyclesCmd{}
+
+	cli := clim.New("cycles", "find dependency cycles (fails if there are any)",
+		cyclesCmd.Run)
+
+	cli.AddFlag(&clim.Flag{
+		Value: clim.String(&cyclesCmd.JQL, ""),
+		Long:  "jql", Label: "QUERY",
+		Help:     "JQL query, for example: 'project = \"MY PROJECT\"''. An empty string is not accepted because it would query ALL the projects in the Jira instance",
+		Required: true,
+	})
+	cli.AddFlag(&clim.Flag{
+		Value: clim.StringSlice(&cyclesCmd.LinkTypes, dependencyLinkTypes),
+		Long:  "link-types", Label: "type[,type,..]",
+		Help: "Link types that form a dependency",
+	})
+
+	return cli
+}
+
+// dependencyLinkTypes are the link types that form a dependency, unless
+// specified otherwise.
+var dependencyLinkTypes = []string{"blocks"}
+
+func (cmd *cyclesCmd) Run(app App) error {
+	client, err := app.newClient()
+	if err != nil {
+		return fmt.Errorf("cycles: %w", err)
+	}
+
+	g, err := searchGraph(context.Background(), client, cmd.JQL)
+	if err != nil {
+		return fmt.Errorf("cycles: %s", err)
+	}
+
+	cycles := findCycles(g, cmd.LinkTypes)
+	if len(cycles) == 0 {
+		fmt.Println("no cycles")
+		return nil
+	}
+	printCycles(os.Stdout, g, cycles)
+	return fmt.Errorf("cycles: found %d dependency cycles", len(cycles))
+}
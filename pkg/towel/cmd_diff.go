@@ -0,0 +1,83 @@
+package towel
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/marco-m/clim"
+)
+
+type diffCmd struct {
+	Snapshots []string // OLD NEW, the outputs of 'jira-towel query'.
+	JSON      bool
+}
+
+// newDiffCLI returns the diff command. Since clim parses only flags, the
+// positional arguments OLD and NEW are taken out of the command line by
+// diffArgs before parsing, and passed here as 'snapshots'.
+func newDiffCLI(snapshots []string) *clim.CLI[App] {
+	diffCmd := diffCmd{Snapshots: snapshots}
+
+	cli := clim.New("diff", "report the changes between two issue snapshots (diff OLD NEW)",
+		diffCmd.Run)
+
+	cli.AddFlag(&clim.Flag{
+		Value: clim.Bool(&diffCmd.JSON, false),
+		Long:  "json",
+		Help:  "Output in JSON instead of text",
+	})
+
+	return cli
+}
+
+// diffArgs splits 'args' (the whole command line) into the arguments to parse
+// with clim and the positional arguments of the diff command: the arguments
+// following "diff" that are not flags. The diff command has only boolean
+// flags, so no positional argument can be the value of a flag.
+func diffArgs(args []string) (rest []string, snapshots []string) {
+	for i, arg := range args {
+		if arg != "diff" {
+			continue
+		}
+		rest = append(rest, args[:i+1]...)
+		for _, arg := range args[i+1:] {
+			if strings.HasPrefix(arg, "-") {
+				rest = append(rest, arg)
+			} else {
+				snapshots = append(snapshots, arg)
+			}
+		}
+		return rest, snapshots
+	}
+	return args, nil
+}
+
+func (cmd *diffCmd) Run(app App) error {
+	if len(cmd.Snapshots) != 2 {
+		return clim.ParseError("diff: want 2 snapshots (OLD NEW), have %d",
+			len(cmd.Snapshots))
+	}
+	oldIssues, err := loadSnapshot(cmd.Snapshots[0])
+	if err != nil {
+		return fmt.Errorf("diff: %s", err)
+	}
+	newIssues, err := loadSnapshot(cmd.Snapshots[1])
+	if err != nil {
+		return fmt.Errorf("diff: %s", err)
+	}
+
+	diff := diffIssues(oldIssues, newIssues)
+
+	if cmd.JSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "    ")
+		if err := enc.Encode(diff); err != nil {
+			return fmt.Errorf("diff: JSON: %s", err)
+		}
+		return nil
+	}
+	diff.writeText(os.Stdout)
+	return nil
+}
@@ -1,30 +1,50 @@
 package towel
 
 import (
-	"encoding/json"
+	"bytes"
+	"context"
 	"fmt"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 
 	"github.com/marco-m/clim"
-	"github.com/marco-m/jira-towel/pkg/text"
-	"github.com/mitchellh/mapstructure"
 )
 
 type graphCmd struct {
-	JQL          string
-	DotPath      string
-	Rankdir      string
-	CustomFields []string
-	CfLUT        map[string]int
-	ClusterBy    string
+	JQL           string
+	DotPath       string
+	Rankdir       string
+	CustomFields  []string
+	CfLUT         map[string]int
+	ClusterBy     []string
+	ClusterMulti  string
+	FollowLinks   int
+	LinkTypes     []string
+	ExcludeLinks  []string
+	DepTypes      []string
+	MaxNodes      int
+	HideStatus    []string
+	CheckCycles   bool
+	CriticalPath  bool
+	MarkBlocked   bool
+	Focus         []string
+	Upstream      int
+	Downstream    int
+	FetchMissing  bool
+	Reduce        bool
+	ListRedundant bool
+	Hierarchy     bool
+	Rollup        string
+	Theme         string
+	Legend        bool
+	NodeLabel     string
+	NodeLabelHTML bool
 }
 
 func newGraphCLI() *clim.CLI[App] {
-	graphCmd := graphCmd{
-		CfLUT: make(map[string]int),
-	}
+	graphCmd := graphCmd{}
 
 	cli := clim.New("graph", "generate the dependency graph of a set of tickets",
 		graphCmd.Run)
@@ -45,203 +65,309 @@ func newGraphCLI() *clim.CLI[App] {
 		Long:  "rankdir",
 		Help:  "DOT rankdir (LR, TB)",
 	})
+	cli.AddFlag(&clim.Flag{
+		Value: clim.String(&graphCmd.Theme, ""),
+		Long:  "theme", Label: "NAME|FILE",
+		Help: "Theme of the graph: default, colorblind or a theme file (default: from the configuration file)",
+	})
+	cli.AddFlag(&clim.Flag{
+		Value: clim.Bool(&graphCmd.Legend, false),
+		Long:  "legend",
+		Help:  "Add a legend explaining the colors, shapes and edge styles used in the graph",
+	})
+	cli.AddFlag(&clim.Flag{
+		Value: clim.String(&graphCmd.NodeLabel, defaultNodeLabel),
+		Long:  "node-label", Label: "TEMPLATE",
+		Help: "Go text/template of the node label, with the ticket fields and functions cf, shorten, wrap, date, join (see README)",
+	})
+	cli.AddFlag(&clim.Flag{
+		Value: clim.Bool(&graphCmd.NodeLabelHTML, false),
+		Long:  "node-label-html",
+		Help:  "The node label is a graphviz HTML-like label (values are escaped for HTML)",
+	})
 	cli.AddFlag(&clim.Flag{
 		Value: clim.StringSlice(&graphCmd.CustomFields, nil),
 		Long:  "custom-fields", Label: "name:id[,name:id,..]",
 		Help: "List of customfield names to IDs (eg: product:37,feature:42)",
 	})
 	cli.AddFlag(&clim.Flag{
-		Value: clim.String(&graphCmd.ClusterBy, ""),
-		Long:  "cluster-by", Label: "CUSTOM-FIELD",
-		Help: "Name of the custom field to cluster by (needs also --custom-fields)",
+		Value: clim.StringSlice(&graphCmd.ClusterBy, nil),
+		Long:  "cluster-by", Label: "field[,field,..]",
+		Help: "Fields to cluster by, one per nesting level: project, assignee, status, component, fixVersion, sprint, label or a name from --custom-fields",
+	})
+	cli.AddFlag(&clim.Flag{
+		Value: clim.String(&graphCmd.ClusterMulti, "first"),
+		Long:  "cluster-multi", Label: "STRATEGY",
+		Help: "How to cluster by a multi-valued field: first (only the first value) or duplicate (draw the ticket once per value)",
+	})
+	cli.AddFlag(&clim.Flag{
+		Value: clim.Bool(&graphCmd.Hierarchy, false),
+		Long:  "hierarchy",
+		Help:  "Cluster by epic and parent: epics contain their stories, stories their subtasks (legacy Epic Link from custom field 'epic-link')",
+	})
+	cli.AddFlag(&clim.Flag{
+		Value: clim.String(&graphCmd.Rollup, ""),
+		Long:  "rollup", Label: "LEVEL",
+		Help: "Collapse each ticket into its epic and show the dependencies between epics (LEVEL: epic)",
+	})
+	cli.AddFlag(&clim.Flag{
+		Value: clim.Int(&graphCmd.FollowLinks, 0),
+		Long:  "follow-links", Label: "DEPTH",
+		Help: "Fetch also the issues linked to the search result, up to DEPTH links away",
+	})
+	cli.AddFlag(&clim.Flag{
+		Value: clim.StringSlice(&graphCmd.LinkTypes, nil),
+		Long:  "link-types", Label: "type[,type,..]",
+		Help: "Link types to draw and to follow with --follow-links (eg: blocks,relates). Default: all",
+	})
+	cli.AddFlag(&clim.Flag{
+		Value: clim.StringSlice(&graphCmd.ExcludeLinks, nil),
+		Long:  "exclude-link-types", Label: "type[,type,..]",
+		Help: "Link types not to draw nor follow (eg: relates,clones)",
+	})
+	cli.AddFlag(&clim.Flag{
+		Value: clim.Int(&graphCmd.MaxNodes, 500),
+		Long:  "max-nodes", Label: "N",
+		Help: "Stop following links when the graph has N issues",
+	})
+	cli.AddFlag(&clim.Flag{
+		Value: clim.StringSlice(&graphCmd.HideStatus, nil),
+		Long:  "hide-status", Label: "status[,status,..]",
+		Help: "Hide the tickets with these statuses or status categories (eg: done), keeping the links through them",
+	})
+	cli.AddFlag(&clim.Flag{
+		Value: clim.StringSlice(&graphCmd.DepTypes, dependencyLinkTypes),
+		Long:  "dependency-types", Label: "type[,type,..]",
+		Help: "Link types that form a dependency, for --check-cycles, --critical-path, --mark-blocked, --focus and --reduce",
+	})
+	cli.AddFlag(&clim.Flag{
+		Value: clim.Bool(&graphCmd.CheckCycles, false),
+		Long:  "check-cycles",
+		Help:  "Highlight the dependency cycles and fail if there are any (see --dependency-types)",
+	})
+	cli.AddFlag(&clim.Flag{
+		Value: clim.StringSlice(&graphCmd.Focus, nil),
+		Long:  "focus", Label: "KEY[,KEY,..]",
+		Help: "Keep only the neighbourhood of these tickets along the dependencies (see --upstream, --downstream)",
+	})
+	cli.AddFlag(&clim.Flag{
+		Value: clim.Int(&graphCmd.Upstream, 1),
+		Long:  "upstream", Label: "N",
+		Help: "With --focus, keep the tickets blocking the focus up to N links away",
+	})
+	cli.AddFlag(&clim.Flag{
+		Value: clim.Int(&graphCmd.Downstream, 1),
+		Long:  "downstream", Label: "N",
+		Help: "With --focus, keep the tickets blocked by the focus up to N links away",
+	})
+	cli.AddFlag(&clim.Flag{
+		Value: clim.Bool(&graphCmd.FetchMissing, false),
+		Long:  "fetch-missing",
+		Help:  "With --focus, fetch also the tickets of the neighbourhood that are not in the search result",
+	})
+	cli.AddFlag(&clim.Flag{
+		Value: clim.Bool(&graphCmd.Reduce, false),
+		Long:  "reduce",
+		Help:  "Remove the dependencies implied by longer chains (A blocks B, B blocks C: A blocks C is redundant)",
+	})
+	cli.AddFlag(&clim.Flag{
+		Value: clim.Bool(&graphCmd.ListRedundant, false),
+		Long:  "list-redundant",
+		Help:  "List the redundant dependencies (see --reduce), to remove them in Jira",
+	})
+	cli.AddFlag(&clim.Flag{
+		Value: clim.Bool(&graphCmd.CriticalPath, false),
+		Long:  "critical-path",
+		Help:  "Highlight the longest chain of dependencies among the unfinished tickets (see --dependency-types; story points from custom field 'points')",
+	})
+	cli.AddFlag(&clim.Flag{
+		Value: clim.Bool(&graphCmd.MarkBlocked, false),
+		Long:  "mark-blocked",
+		Help:  "Mark the unfinished tickets blocked by an unfinished ticket, blocking one, or ready, with a legend (see --dependency-types)",
 	})
 
 	return cli
 }
 
 func (cmd *graphCmd) Run(app App) error {
-	config, err := loadConfig(app.ConfigDir)
+	client, err := app.newClient()
 	if err != nil {
 		return fmt.Errorf("graph: %w", err)
 	}
 
-	for _, kv := range cmd.CustomFields {
-		k, v, found := strings.Cut(kv, ":")
-		if !found {
-			return clim.ParseError("custom-fields: %q: missing separator ':'", kv)
+	theme, err := cmd.loadTheme(app)
+	if err != nil {
+		return fmt.Errorf("graph: %s", err)
+	}
+
+	cmd.CfLUT, err = parseCustomFields(cmd.CustomFields)
+	if err != nil {
+		return err
+	}
+	nodeLabel, err := parseNodeLabel(cmd.NodeLabel, cmd.NodeLabelHTML, cmd.CfLUT)
+	if err != nil {
+		return clim.ParseError("%s", err)
+	}
+
+	if cmd.Hierarchy && len(cmd.ClusterBy) > 0 {
+		return clim.ParseError("hierarchy: mutually exclusive with --cluster-by")
+	}
+
+	if cmd.ClusterMulti != "first" && cmd.ClusterMulti != "duplicate" {
+		return clim.ParseError("cluster-multi: %q: unsupported strategy (supported: first, duplicate)",
+			cmd.ClusterMulti)
+	}
+	if cmd.Rollup != "" && cmd.Rollup != "epic" {
+		return clim.ParseError("rollup: %q: unsupported level (supported: epic)",
+			cmd.Rollup)
+	}
+
+	ctx := context.Background()
+	if _, found := cmd.CfLUT["sprint"]; slices.Contains(cmd.ClusterBy, "sprint") && !found {
+		id, err := sprintFieldID(ctx, client)
+		if err != nil {
+			return fmt.Errorf("graph: %s (see --custom-fields)", err)
 		}
-		id, err := strconv.Atoi(v)
+		cmd.CfLUT["sprint"] = id
+	}
+	if err := checkClusterBy(cmd.ClusterBy, cmd.CfLUT); err != nil {
+		return clim.ParseError("%s", err)
+	}
+
+	if len(cmd.LinkTypes) > 0 || len(cmd.ExcludeLinks) > 0 ||
+		!slices.Equal(cmd.DepTypes, dependencyLinkTypes) {
+		known, err := client.LinkTypes(ctx)
 		if err != nil {
-			return clim.ParseError("custom-fields: %q: %q is not a number: %s",
-				kv, v, err)
+			return fmt.Errorf("graph: %s", err)
+		}
+		names := slices.Concat(cmd.LinkTypes, cmd.ExcludeLinks, cmd.DepTypes)
+		if err := checkLinkTypes(names, known); err != nil {
+			return clim.ParseError("%s", err)
 		}
-		cmd.CfLUT[k] = id
 	}
 
-	jsonResponses, count, err := doQuery(app.HttpClient, config, cmd.JQL)
+	g, err := searchGraph(ctx, client, cmd.JQL)
 	if err != nil {
 		return fmt.Errorf("graph: %s", err)
 	}
-	issues := make([]issue, 0, count)
 
-	for _, jsonresp := range jsonResponses {
-		var parsedMap map[string]any
-		if err := json.Unmarshal(jsonresp, &parsedMap); err != nil {
-			return fmt.Errorf("query: JSON: %s", err)
+	printSummary(g.Issues())
+	if accept := linkTypeFilter(cmd.LinkTypes, cmd.ExcludeLinks); accept != nil {
+		g.setLinkFilter(accept)
+	}
+
+	if cmd.FollowLinks > 0 {
+		truncated, err := followLinks(ctx, client, g, crawlOptions{
+			depth:     cmd.FollowLinks,
+			linkTypes: cmd.LinkTypes,
+			maxNodes:  cmd.MaxNodes,
+		})
+		if err != nil {
+			return fmt.Errorf("graph: following links: %s", err)
 		}
-		var queryResp queryResponse
-		if err = mapstructure.Decode(parsedMap, &queryResp); err != nil {
-			return fmt.Errorf("query: mapstructure: %s", err)
+		if truncated {
+			fmt.Fprintf(os.Stderr,
+				"graph: stopped following links: reached %d issues (see --max-nodes)\n",
+				cmd.MaxNodes)
 		}
-		issues = append(issues, queryResp.Issues...)
 	}
 
-	printSummary(issues)
-
-	dot := makeGraph(issues, cmd.Rankdir, cmd.CfLUT, cmd.ClusterBy)
-	if err := os.WriteFile(cmd.DotPath, []byte(dot), 0o660); err != nil {
-		return fmt.Errorf("writing %s: %s", cmd.DotPath, err)
+	if len(cmd.HideStatus) > 0 {
+		hidden := hideIssues(g, cmd.HideStatus)
+		fmt.Fprintf(os.Stderr, "graph: hidden %d issues\n", hidden)
 	}
-	return nil
-}
 
-func makeGraph(issues []issue, rankdir string, lut map[string]int, clusterby string) string {
-	var bld strings.Builder
-	fmt.Fprintln(&bld, "digraph {")
-	fmt.Fprintf(&bld, "    rankdir=%s\n", rankdir)
-	fmt.Fprintln(&bld, `    node [shape=box style=filled width=3.5 height=0.5 fixedsize="true"]`)
-	fmt.Fprintln(&bld)
+	depLinkTypes := cmd.DepTypes
 
-	indent := "    "
-	clusters := make(map[string][]string)
-
-	for _, ticket := range issues {
-		clusterName := CustomfieldValue(ticket.Fields.CustomFields, lut, clusterby)
-		clusters[clusterName] = append(clusters[clusterName], ticket.Key)
-		fmt.Fprintln(&bld, makeNode(ticket, indent))
-		for _, edge := range makeEdges(ticket, indent) {
-			fmt.Fprintln(&bld, edge)
+	if len(cmd.Focus) > 0 {
+		opts := focusOptions{
+			keys:       cmd.Focus,
+			upstream:   cmd.Upstream,
+			downstream: cmd.Downstream,
+			linkTypes:  depLinkTypes,
+		}
+		if cmd.FetchMissing {
+			opts.client = client
+		}
+		g, err = focusGraph(ctx, g, opts)
+		if err != nil {
+			return fmt.Errorf("graph: %s", err)
 		}
 	}
 
-	fmt.Fprintln(&bld, makeClusters(clusters, indent))
-
-	fmt.Fprintln(&bld, "}")
-	return bld.String()
-}
-
-//	subgraph cluster_0 {
-//		label = "process #1";
-//		style=filled;
-//		color=lightgrey;
-//		node [style=filled,color=white];
-//		a0 -> a1 -> a2 -> a3;
-//	}
-func makeClusters(clusters map[string][]string, indent string) string {
-	var bld strings.Builder
-	invisible := 0
-	for clusterName, nodeNames := range clusters {
-
-		// hack graphviz bug. Invisible cluster
-		// https://forum.graphviz.org/t/how-to-add-space-between-clusters/1209
-		invisible++
-		fmt.Fprintf(&bld, "%ssubgraph cluster_wrap_%d {\n", indent, invisible)
-		fmt.Fprintf(&bld, "%scolor=%q\n", indent, "white")
-
-		if clusterName == "" {
-			clusterName = "unknown"
+	if cmd.Reduce || cmd.ListRedundant {
+		redundant := redundantEdges(g, depLinkTypes)
+		if cmd.ListRedundant {
+			printRedundant(os.Stdout, redundant)
 		}
-		fmt.Fprintf(&bld, "%ssubgraph \"cluster_%s\" {\n", indent, clusterName)
-		// sigh this is internal margin!
-		// fmt.Fprintf(&bld, "margin=55\n")
-		fmt.Fprintf(&bld, "%s%slabel=%q style=filled color=%q\n", indent, indent,
-			clusterName, "aquamarine")
-		for _, nodeName := range nodeNames {
-			fmt.Fprintf(&bld, "%s%s%q\n", indent, indent, nodeName)
+		if cmd.Reduce {
+			reduceGraph(g, redundant)
 		}
-		fmt.Fprintf(&bld, "%s}\n", indent)
+	}
 
-		// close wrap cluster hack see above
-		fmt.Fprintf(&bld, "%s}\n", indent)
+	if cmd.Rollup == "epic" {
+		g = rollupByEpic(g, cmd.CfLUT)
 	}
-	return bld.String()
-}
 
-func makeNode(ticket issue, indent string) string {
-	const maxWidth = 40
-	// if ticket.Fields.IssueType.Name == "Epic" {
-	// 	return ""
-	// }
-	key := ticket.Key
-	status := ticket.Fields.Status.Name
-	// HACK
-	// product := ticket.Fields.Product.Name
-	// parent := "towel bug: no parent?"
-	// FIXME this is enough not to crash but it means we still miss the parent
-	//   epic for some reasons I do not understand.
-	// if ticket.Fields.Parent != nil {
-	// 	parent = ticket.Fields.Parent.Fields.Summary
-	// }
-	summary := ticket.Fields.Summary
-	// label := fmt.Sprintf("%s\n(%s)\n%s %s",
-	// 	text.ShortenMiddle(summary, maxWidth), text.ShortenMiddle(parent, maxWidth),
-	// 	key, status)
-	label := fmt.Sprintf("%s\n%s %s",
-		text.ShortenMiddle(summary, maxWidth), key, status)
-	return fmt.Sprintf("%s%q [label=%q fillcolor=%q]",
-		indent, key, label, nodeColor(status))
-}
+	renderer := dotRenderer{
+		rankdir:          cmd.Rankdir,
+		lut:              cmd.CfLUT,
+		clusterBy:        cmd.ClusterBy,
+		clusterDuplicate: cmd.ClusterMulti == "duplicate",
+		hierarchy:        cmd.Hierarchy,
+		legend:           cmd.Legend,
+		theme:            theme,
+		nodeLabel:        nodeLabel,
+		browseURL:        client.BrowseURL,
+	}
+	if cmd.MarkBlocked {
+		renderer.states = dependencyStates(g, depLinkTypes)
+	}
+	var cycles [][]string
+	if cmd.CheckCycles {
+		cycles = findCycles(g, depLinkTypes)
+		renderer.cycleEdges = cycleEdges(g, cycles, depLinkTypes)
+	}
+	if cmd.CriticalPath && len(cycles) == 0 {
+		wgt := chooseWeight(g.Issues(), cmd.CfLUT)
+		path, total, err := findCriticalPath(g, depLinkTypes, wgt)
+		if err != nil {
+			return fmt.Errorf("graph: critical path: %s", err)
+		}
+		renderer.criticalEdges = criticalEdges(g, path, depLinkTypes)
+		printCriticalPath(os.Stdout, g, path, total, wgt)
+	}
 
-func nodeColor(status string) string {
-	switch strings.ToLower(status) {
-	case "to do":
-		return "cadetblue1"
-	case "in progress":
-		return "orange"
-	case "done":
-		return "yellowgreen"
-	default:
-		return "gray"
+	var buf bytes.Buffer
+	if err := renderer.Render(&buf, g); err != nil {
+		return fmt.Errorf("graph: %s", err)
+	}
+	if err := os.WriteFile(cmd.DotPath, buf.Bytes(), 0o660); err != nil {
+		return fmt.Errorf("writing %s: %s", cmd.DotPath, err)
 	}
-}
 
-func makeEdges(ticket issue, indent string) []string {
-	var output []string
-	links := ticket.Fields.Issuelinks
-	src := ticket.Key
-	var dst string
-	var relation string
-	// FIXME with this simplistic approach (skip the notion of graph),
-	//   I cannot then decorate the dst with the red border if the
-	//   relation is "blocks" :-(
-	for _, link := range links {
-		// NOTE It should be impossible to have both Outward and Inward at
-		// the same time...
-		if link.OutwardIssue.Key != "" {
-			dst = link.OutwardIssue.Key
-			relation = link.Type.Outward
-			output = append(output,
-				fmt.Sprintf("%s%q -> %q [label=%q color=%q]\n",
-					indent, src, dst, relation, edgeColor(relation)))
-		}
-		//if link.InwardIssue.Key != "" {
-		//	dst = link.InwardIssue.Key
-		//	relation = link.Type.Inward
-		//}
+	if len(cycles) > 0 {
+		printCycles(os.Stdout, g, cycles)
+		return fmt.Errorf("graph: found %d dependency cycles", len(cycles))
 	}
-	return output
+	return nil
 }
 
-func edgeColor(relation string) string {
-	switch strings.ToLower(relation) {
-	case "blocks":
-		return "red"
-	default:
-		return "black"
+// loadTheme loads the theme from flag --theme or, if not set, from the
+// configuration file. A relative path is relative to the current directory
+// for the flag, and to the configuration directory for the configuration file.
+func (cmd *graphCmd) loadTheme(app App) (*Theme, error) {
+	if cmd.Theme != "" {
+		return loadTheme(cmd.Theme, ".")
+	}
+	config, err := LoadConfig(app.ConfigDir)
+	if err != nil {
+		return nil, err
 	}
+	return loadTheme(config.Theme, app.ConfigDir)
 }
 
-func printSummary(issues []issue) {
+func printSummary(issues []Issue) {
 	fmt.Printf("received %d issues\n", len(issues))
 	for _, ticket := range issues {
 		fmt.Println("============")
@@ -252,22 +378,22 @@ func printSummary(issues []issue) {
 	}
 }
 
-func printFirstLine(issue issue) {
+func printFirstLine(ticket Issue) {
 	fmt.Printf("%s (%s) %s\n",
-		issue.Key, issue.Fields.IssueType.Name, issue.Fields.Summary)
+		ticket.Key, ticket.Fields.IssueType.Name, ticket.Fields.Summary)
 }
 
-func printParent(issue issue) {
+func printParent(ticket Issue) {
 	fmt.Print("parent: ")
-	if issue.Fields.Parent != nil {
-		printFirstLine(*issue.Fields.Parent)
+	if ticket.Fields.Parent != nil {
+		printFirstLine(*ticket.Fields.Parent)
 	} else {
 		fmt.Println("<none>")
 	}
 }
 
-func printRelations(issue issue) {
-	issueLinks := issue.Fields.Issuelinks
+func printRelations(ticket Issue) {
+	issueLinks := ticket.Fields.Issuelinks
 	for _, link := range issueLinks {
 		// NOTE It should be impossible to have both Outward and Inward.
 		if link.OutwardIssue.Key != "" {
@@ -278,3 +404,22 @@ func printRelations(issue issue) {
 		}
 	}
 }
+
+// parseCustomFields parses the value of flag --custom-fields, a list of
+// name:id, into a lookup table for CustomfieldValue.
+func parseCustomFields(customFields []string) (map[string]int, error) {
+	lut := make(map[string]int, len(customFields))
+	for _, kv := range customFields {
+		k, v, found := strings.Cut(kv, ":")
+		if !found {
+			return nil, clim.ParseError("custom-fields: %q: missing separator ':'", kv)
+		}
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, clim.ParseError("custom-fields: %q: %q is not a number: %s",
+				kv, v, err)
+		}
+		lut[k] = id
+	}
+	return lut, nil
+}
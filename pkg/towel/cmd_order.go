@@ -0,0 +1,66 @@
+package towel
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/marco-m/clim"
+)
+
+type orderCmd struct {
+	JQL          string
+	LinkTypes    []string
+	CustomFields []string
+}
+
+func newOrderCLI() *clim.CLI[App] {
+	orderCmd := orderCmd{}
+
+	cli := clim.New("order",
+		"list the tickets in execution order, in waves that can be worked on in parallel",
+		orderCmd.Run)
+
+	cli.AddFlag(&clim.Flag{
+		Value: clim.String(&orderCmd.JQL, ""),
+		Long:  "jql", Label: "QUERY",
+		Help:     "JQL query, for example: 'project = \"MY PROJECT\"''. An empty string is not accepted because it would query ALL the projects in the Jira instance",
+		Required: true,
+	})
+	cli.AddFlag(&clim.Flag{
+		Value: clim.StringSlice(&orderCmd.LinkTypes, dependencyLinkTypes),
+		Long:  "link-types", Label: "type[,type,..]",
+		Help: "Link types that form a dependency",
+	})
+	cli.AddFlag(&clim.Flag{
+		Value: clim.StringSlice(&orderCmd.CustomFields, nil),
+		Long:  "custom-fields", Label: "name:id[,name:id,..]",
+		Help: "List of customfield names to IDs. Name 'rank' is the backlog rank (eg: rank:10019)",
+	})
+
+	return cli
+}
+
+func (cmd *orderCmd) Run(app App) error {
+	lut, err := parseCustomFields(cmd.CustomFields)
+	if err != nil {
+		return err
+	}
+
+	client, err := app.newClient()
+	if err != nil {
+		return fmt.Errorf("order: %w", err)
+	}
+
+	g, err := searchGraph(context.Background(), client, cmd.JQL)
+	if err != nil {
+		return fmt.Errorf("order: %s", err)
+	}
+
+	waves, err := workOrder(g, cmd.LinkTypes, lut)
+	if err != nil {
+		return fmt.Errorf("order: %s", err)
+	}
+	printWaves(os.Stdout, waves)
+	return nil
+}
@@ -1,6 +1,7 @@
 package towel
 
 import (
+	"context"
 	"fmt"
 	"os"
 
@@ -28,20 +29,20 @@ func newQueryCLI() *clim.CLI[App] {
 }
 
 func (cmd *queryCmd) Run(app App) error {
-	config, err := loadConfig(app.ConfigDir)
+	client, err := app.newClient()
 	if err != nil {
 		return fmt.Errorf("query: %w", err)
 	}
 
-	jsonResponses, count, err := doQuery(app.HttpClient, config, cmd.JQL)
-	if err != nil {
-		return fmt.Errorf("query: %s", err)
-	}
-
-	fmt.Fprintln(os.Stderr, "total:", count)
-	for _, resp := range jsonResponses {
-		fmt.Println(string(resp))
+	total := 0
+	for page, err := range client.SearchPages(context.Background(), cmd.JQL) {
+		if err != nil {
+			return fmt.Errorf("query: %s", err)
+		}
+		total = page.Total
+		fmt.Println(string(page.Raw))
 	}
+	fmt.Fprintln(os.Stderr, "total:", total)
 
 	// var queryResp queryResponse
 	// if err := json.Unmarshal(jsonResponse, &queryResp); err != nil {
@@ -10,15 +10,19 @@ import (
 	"path/filepath"
 )
 
+// Config is the content of the configuration file, jira-towel.json.
 type Config struct {
 	Version  int    `json:"version"`
 	Email    string `json:"email"`
 	ApiToken string `json:"api_token"`
 	Server   string `json:"server"`
+	// Theme of the graph: a built-in theme ("default", "colorblind") or the
+	// path of a theme file, relative to the configuration directory. Optional.
+	Theme string `json:"theme,omitempty"`
 }
 
-// loadConfig parses and validates the configuration file.
-func loadConfig(configDir string) (Config, error) {
+// LoadConfig parses and validates the configuration file.
+func LoadConfig(configDir string) (Config, error) {
 	configFile := configFile(configDir)
 	file, err := os.Open(configFile)
 	if err != nil {
@@ -103,9 +107,9 @@ func initConfig(configDir string) error {
 	return nil
 }
 
-// defaultConfigDir returns the OS-default configuration directory for
+// DefaultConfigDir returns the OS-default configuration directory for
 // jira-towel.
-func defaultConfigDir() (string, error) {
+func DefaultConfigDir() (string, error) {
 	baseConfigDir, err := os.UserConfigDir()
 	if err != nil {
 		return "", fmt.Errorf("retrieving the user configuration directory: %w", err)
@@ -0,0 +1,117 @@
+package towel
+
+import (
+	"context"
+	"slices"
+	"strings"
+)
+
+// crawlOptions controls followLinks.
+type crawlOptions struct {
+	// Number of links to follow, starting from the issues of the search result.
+	depth int
+	// Link types to follow; all if empty. See matchLinkType.
+	linkTypes []string
+	// Maximum number of non-placeholder nodes in the graph.
+	maxNodes int
+}
+
+// followLinks fetches the issues that are linked to the issues in 'g' but are
+// not in 'g' (the placeholders), and adds them to 'g' marked as External. It
+// repeats the process 'opts.depth' times, following links in both directions.
+//
+// To guard against explosions, it stops when the graph reaches opts.maxNodes
+// non-placeholder nodes, and returns truncated == true.
+func followLinks(ctx context.Context, client *Client, g *Graph, opts crawlOptions,
+) (truncated bool, err error) {
+	fetched := 0
+	var frontier []string
+	for _, node := range g.Nodes() {
+		if !node.Placeholder {
+			fetched++
+			frontier = append(frontier, node.Key)
+		}
+	}
+
+	for range opts.depth {
+		missing := missingNeighbours(g, frontier, opts.linkTypes)
+		if len(missing) == 0 {
+			return false, nil
+		}
+		if fetched+len(missing) > opts.maxNodes {
+			missing = missing[:max(opts.maxNodes-fetched, 0)]
+			truncated = true
+		}
+
+		n, err := fetchExternal(ctx, client, g, missing)
+		fetched += n
+		if err != nil {
+			return truncated, err
+		}
+		if truncated {
+			return true, nil
+		}
+		frontier = missing
+	}
+	return false, nil
+}
+
+// fetchExternal fetches the issues with 'keys' and adds them to 'g' marked as
+// External. It returns the number of fetched issues.
+func fetchExternal(ctx context.Context, client *Client, g *Graph, keys []string,
+) (int, error) {
+	fetched := 0
+	for ticket, err := range client.GetIssues(ctx, keys) {
+		if err != nil {
+			return fetched, err
+		}
+		g.AddIssue(ticket)
+		node, _ := g.Node(ticket.Key)
+		node.External = true
+		fetched++
+	}
+	return fetched, nil
+}
+
+// missingNeighbours returns the sorted keys of the placeholder nodes directly
+// linked to 'keys' by a link of one of 'linkTypes' (all if empty).
+func missingNeighbours(g *Graph, keys []string, linkTypes []string) []string {
+	seen := make(map[string]bool)
+	var missing []string
+	visit := func(edge *Edge, key string) {
+		if !matchLinkType(edge.Type, linkTypes) || seen[key] {
+			return
+		}
+		seen[key] = true
+		if node, found := g.Node(key); found && node.Placeholder {
+			missing = append(missing, key)
+		}
+	}
+	for _, key := range keys {
+		for _, edge := range g.OutEdges(key) {
+			visit(edge, edge.To)
+		}
+		for _, edge := range g.InEdges(key) {
+			visit(edge, edge.From)
+		}
+	}
+	slices.SortFunc(missing, compareKeys)
+	return missing
+}
+
+// matchLinkType returns true if 'linkType' matches one of 'names', or if
+// 'names' is empty. A name matches, case-insensitively, either the name of the
+// link type ("Blocks") or one of its descriptions ("blocks", "is blocked by").
+func matchLinkType(linkType LinkType, names []string) bool {
+	if len(names) == 0 {
+		return true
+	}
+	for _, name := range names {
+		if strings.EqualFold(name, linkType.Name) ||
+			strings.EqualFold(name, linkType.Outward) ||
+			strings.EqualFold(name, linkType.Inward) {
+			return true
+		}
+	}
+	return false
+}
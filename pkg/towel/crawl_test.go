@@ -0,0 +1,144 @@
+package towel
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/marco-m/rosina"
+)
+
+// newIssueServer returns a client connected to a fake Jira server containing
+// 'issues'. The server understands only JQL "key in (K1, K2, ...)"; any other
+// JQL returns all the issues. It also returns the list of received JQL queries.
+func newIssueServer(t *testing.T, issues ...Issue) (*Client, *[]string) {
+	byKey := make(map[string]Issue, len(issues))
+	for _, ticket := range issues {
+		byKey[ticket.Key] = ticket
+	}
+	var queries []string
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			var req queryRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			queries = append(queries, req.JQL)
+
+			resp := queryResponse{}
+			if list, found := strings.CutPrefix(req.JQL, "key in ("); found {
+				for _, key := range strings.Split(strings.TrimSuffix(list, ")"), ",") {
+					if ticket, found := byKey[strings.TrimSpace(key)]; found {
+						resp.Issues = append(resp.Issues, ticket)
+					}
+				}
+			} else {
+				resp.Issues = issues
+			}
+			resp.MaxResults = len(resp.Issues)
+			resp.Total = len(resp.Issues)
+			if err := json.NewEncoder(w).Encode(resp); err != nil {
+				t.Error(err)
+			}
+		}))
+	t.Cleanup(srv.Close)
+
+	config := Config{Server: strings.TrimPrefix(srv.URL, "https://")}
+	return NewClient(config, WithHTTPClient(srv.Client())), &queries
+}
+
+func TestFollowLinks(t *testing.T) {
+	relates := LinkType{Name: "Relates", Inward: "relates to", Outward: "relates to"}
+	// A-1 -> B-1 -> C-1 -> D-1, plus B-1 is blocked by X-1, plus A-1 relates to R-1.
+	a1 := newIssue("A-1", "To Do", "B-1")
+	a1.Fields.Issuelinks = append(a1.Fields.Issuelinks,
+		IssueLink{Type: relates, OutwardIssue: Issue{Key: "R-1"}})
+	b1 := newIssue("B-1", "To Do", "C-1")
+	b1.Fields.Issuelinks = append(b1.Fields.Issuelinks, isBlockedBy("X-1"))
+	server := []Issue{a1, b1, newIssue("C-1", "To Do", "D-1"),
+		newIssue("D-1", "To Do"), newIssue("X-1", "Done", "B-1"),
+		newIssue("R-1", "To Do")}
+
+	type testCase struct {
+		name          string
+		opts          crawlOptions
+		wantFetched   string
+		wantTruncated bool
+	}
+
+	testCases := []testCase{
+		{
+			name:        "depth 1",
+			opts:        crawlOptions{depth: 1, maxNodes: 100},
+			wantFetched: "A-1 B-1 R-1",
+		},
+		{
+			name:        "depth 2 follows also inward links",
+			opts:        crawlOptions{depth: 2, maxNodes: 100},
+			wantFetched: "A-1 B-1 R-1 C-1 X-1",
+		},
+		{
+			name:        "depth 3",
+			opts:        crawlOptions{depth: 3, maxNodes: 100},
+			wantFetched: "A-1 B-1 R-1 C-1 X-1 D-1",
+		},
+		{
+			name:        "only some link types",
+			opts:        crawlOptions{depth: 3, linkTypes: []string{"blocks"}, maxNodes: 100},
+			wantFetched: "A-1 B-1 C-1 X-1 D-1",
+		},
+		{
+			name:          "max nodes",
+			opts:          crawlOptions{depth: 3, maxNodes: 3},
+			wantFetched:   "A-1 B-1 R-1",
+			wantTruncated: true,
+		},
+	}
+
+	test := func(t *testing.T, tc testCase) {
+		client, _ := newIssueServer(t, server...)
+		g := NewGraph()
+		g.AddIssue(a1)
+
+		truncated, err := followLinks(context.Background(), client, g, tc.opts)
+		rosina.AssertNoError(t, err)
+
+		var fetched []*Node
+		for _, node := range g.Nodes() {
+			if !node.Placeholder {
+				fetched = append(fetched, node)
+			}
+		}
+		rosina.AssertEqual(t, nodeKeys(fetched), tc.wantFetched, "fetched")
+		rosina.AssertEqual(t, truncated, tc.wantTruncated, "truncated")
+		node, _ := g.Node("A-1")
+		rosina.AssertEqual(t, node.External, false, "A-1 is from the search")
+		node, _ = g.Node("B-1")
+		rosina.AssertEqual(t, node.External, true, "B-1 is external")
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) { test(t, tc) })
+	}
+}
+
+func TestFollowLinksBatchesByKey(t *testing.T) {
+	client, queries := newIssueServer(t, newIssue("B-1", "To Do"),
+		newIssue("B-2", "To Do"))
+	g := NewGraph()
+	g.AddIssue(newIssue("A-1", "To Do", "B-2", "B-1", "B-3"))
+
+	_, err := followLinks(context.Background(), client, g,
+		crawlOptions{depth: 1, maxNodes: 100})
+	rosina.AssertNoError(t, err)
+
+	rosina.AssertEqual(t, strings.Join(*queries, "; "), "key in (B-1, B-2, B-3)",
+		"queries")
+	node, _ := g.Node("B-3")
+	rosina.AssertEqual(t, node.Placeholder, true, "B-3 does not exist")
+}
@@ -0,0 +1,223 @@
+package towel
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+)
+
+// pointsField is the name of the custom field (see --custom-fields) holding
+// the story points.
+const pointsField = "points"
+
+// weight is how much an issue contributes to the length of a dependency
+// chain.
+type weight struct {
+	unit string // For example "story points".
+	// The weight of an issue, and false if unknown. An explicit 0 (for example
+	// 0 story points) is known.
+	own func(ticket Issue) (float64, bool)
+	// The weight of the issues with unknown weight.
+	fallback float64
+}
+
+// value returns the weight of 'ticket': its own if known, else the fallback.
+func (w weight) value(ticket Issue) float64 {
+	if value, ok := w.own(ticket); ok {
+		return value
+	}
+	return w.fallback
+}
+
+// chooseWeight returns the weight to use for the unfinished issues among
+// 'issues' (the non-placeholder issues of the graph, see Graph.Issues): story
+// points if at least one of them has them, else original estimate if at least
+// one of them has it, else one per issue. Since estimates are often partial,
+// an issue without the chosen value weighs the mean of the unfinished issues
+// that have it; weighing it 0 would make any chain of unestimated issues
+// shorter than a single estimated one.
+func chooseWeight(issues []Issue, lut map[string]int) weight {
+	points := func(ticket Issue) (float64, bool) {
+		cf := lookupCustomField(ticket.Fields.CustomFields, lut, pointsField)
+		return cf.Number()
+	}
+	hours := func(ticket Issue) (float64, bool) {
+		tracking := ticket.Fields.TimeTracking
+		if tracking.OriginalEstimate == "" {
+			return 0, false
+		}
+		return float64(tracking.OriginalEstimateSeconds) / 3600, true
+	}
+
+	for _, candidate := range []weight{
+		{unit: "story points", own: points},
+		{unit: "hours", own: hours},
+	} {
+		var sum float64
+		count := 0
+		for _, ticket := range issues {
+			if isDone(ticket) {
+				continue
+			}
+			if value, ok := candidate.own(ticket); ok {
+				sum += value
+				count++
+			}
+		}
+		if count > 0 {
+			candidate.fallback = sum / float64(count)
+			return candidate
+		}
+	}
+	return weight{
+		unit: "issues",
+		own:  func(Issue) (float64, bool) { return 1, true },
+	}
+}
+
+// errCycles is returned by findCriticalPath when the dependencies have cycles.
+var errCycles = errors.New("the dependencies have cycles (see command cycles)")
+
+// findCriticalPath returns the longest chain of dependencies of 'g' (edges of
+// 'linkTypes'; see matchLinkType) among the unfinished issues, together with
+// its total weight. Placeholders are not considered, since their weight is
+// unknown. Among chains of the same weight, the one with more issues wins.
+func findCriticalPath(g *Graph, linkTypes []string, w weight) ([]string, float64, error) {
+	type score struct {
+		weight float64
+		count  int
+	}
+	compareScores := func(a, b score) int {
+		return cmp.Or(cmp.Compare(a.weight, b.weight), cmp.Compare(a.count, b.count))
+	}
+
+	inPlay := func(key string) bool {
+		node, found := g.Node(key)
+		return found && !node.Placeholder && !isDone(node.Issue)
+	}
+	dependencies := func(edges []*Edge) []*Edge {
+		var deps []*Edge
+		for _, edge := range edges {
+			if matchLinkType(edge.Type, linkTypes) && inPlay(edge.From) &&
+				inPlay(edge.To) {
+				deps = append(deps, edge)
+			}
+		}
+		return deps
+	}
+
+	// Kahn's algorithm: visit each issue after all the issues blocking it,
+	// keeping for each issue the best chain ending there.
+	inDegree := make(map[string]int)
+	var ready []string
+	for _, node := range g.Nodes() {
+		if !inPlay(node.Key) {
+			continue
+		}
+		inDegree[node.Key] = len(dependencies(g.InEdges(node.Key)))
+		if inDegree[node.Key] == 0 {
+			ready = append(ready, node.Key)
+		}
+	}
+
+	best := make(map[string]score, len(inDegree))
+	prev := make(map[string]string, len(inDegree))
+	var end string
+	visited := 0
+	for len(ready) > 0 {
+		slices.SortFunc(ready, compareKeys)
+		key := ready[0]
+		ready = ready[1:]
+		visited++
+
+		node, _ := g.Node(key)
+		own := score{weight: w.value(node.Issue), count: 1}
+		current := own
+		for _, edge := range dependencies(g.InEdges(key)) {
+			candidate := score{
+				weight: best[edge.From].weight + own.weight,
+				count:  best[edge.From].count + 1,
+			}
+			if compareScores(candidate, current) > 0 ||
+				compareScores(candidate, current) == 0 && prev[key] != "" &&
+					compareKeys(edge.From, prev[key]) < 0 {
+				current = candidate
+				prev[key] = edge.From
+			}
+		}
+		best[key] = current
+		if end == "" || compareScores(current, best[end]) > 0 {
+			end = key
+		}
+
+		for _, edge := range dependencies(g.OutEdges(key)) {
+			inDegree[edge.To]--
+			if inDegree[edge.To] == 0 {
+				ready = append(ready, edge.To)
+			}
+		}
+	}
+	if visited < len(inDegree) {
+		return nil, 0, errCycles
+	}
+	if end == "" {
+		return nil, 0, nil
+	}
+
+	var path []string
+	for key := end; key != ""; key = prev[key] {
+		path = append(path, key)
+	}
+	slices.Reverse(path)
+	return path, best[end].weight, nil
+}
+
+// criticalEdges returns the edges of 'g' between consecutive issues of 'path',
+// as returned by findCriticalPath with the same 'linkTypes'.
+func criticalEdges(g *Graph, path []string, linkTypes []string) map[*Edge]bool {
+	edges := make(map[*Edge]bool)
+	for i := 1; i < len(path); i++ {
+		for _, edge := range g.OutEdges(path[i-1]) {
+			if edge.To == path[i] && matchLinkType(edge.Type, linkTypes) {
+				edges[edge] = true
+			}
+		}
+	}
+	return edges
+}
+
+// printCriticalPath prints 'path' in human-readable form.
+func printCriticalPath(w io.Writer, g *Graph, path []string, total float64, wgt weight) {
+	if len(path) == 0 {
+		fmt.Fprintln(w, "no unfinished issues")
+		return
+	}
+	if wgt.unit == "issues" {
+		fmt.Fprintf(w, "critical path: %d issues\n", len(path))
+	} else {
+		fmt.Fprintf(w, "critical path: %d issues, %g %s\n", len(path), round1(total),
+			wgt.unit)
+	}
+	for _, key := range path {
+		node, _ := g.Node(key)
+		own, known := wgt.own(node.Issue)
+		switch {
+		case wgt.unit == "issues":
+			fmt.Fprintf(w, "  %s %s\n", key, node.Issue.Fields.Summary)
+		case known:
+			fmt.Fprintf(w, "  %s (%g) %s\n", key, own, node.Issue.Fields.Summary)
+		default:
+			// Not estimated: it weighs the mean of the estimated issues.
+			fmt.Fprintf(w, "  %s (~%g) %s\n", key, round1(wgt.value(node.Issue)),
+				node.Issue.Fields.Summary)
+		}
+	}
+}
+
+// round1 returns 'x' rounded to one decimal, for printing.
+func round1(x float64) float64 {
+	return math.Round(x*10) / 10
+}
@@ -0,0 +1,239 @@
+package towel
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/marco-m/rosina"
+)
+
+func TestFindCriticalPath(t *testing.T) {
+	type testCase struct {
+		name     string
+		issues   []Issue
+		lut      map[string]int
+		want     string
+		wantUnit string
+	}
+
+	withPoints := func(ticket Issue, points string) Issue {
+		ticket.Fields.CustomFields = map[string]CustomField{
+			"customfield_10016": CustomField(json.RawMessage(points)),
+		}
+		return ticket
+	}
+	withEstimate := func(ticket Issue, hours int) Issue {
+		ticket.Fields.TimeTracking.OriginalEstimate = fmt.Sprintf("%dh", hours)
+		ticket.Fields.TimeTracking.OriginalEstimateSeconds = hours * 3600
+		return ticket
+	}
+	points := map[string]int{"points": 10016}
+	// chain returns 'n' unestimated issues of 'project', each blocking the next.
+	chain := func(project string, n int) []Issue {
+		var issues []Issue
+		for i := 1; i <= n; i++ {
+			key := fmt.Sprintf("%s-%d", project, i)
+			if i < n {
+				issues = append(issues, newIssue(key, "To Do", fmt.Sprintf("%s-%d", project, i+1)))
+			} else {
+				issues = append(issues, newIssue(key, "To Do"))
+			}
+		}
+		return issues
+	}
+
+	testCases := []testCase{
+		{
+			name:     "empty",
+			want:     "[] 0",
+			wantUnit: "issues",
+		},
+		{
+			name: "longest chain by count",
+			issues: []Issue{
+				newIssue("A-1", "To Do", "A-2", "A-4"),
+				newIssue("A-2", "To Do", "A-3"),
+				newIssue("A-3", "To Do"),
+				newIssue("A-4", "To Do"),
+			},
+			want:     "[A-1 A-2 A-3] 3",
+			wantUnit: "issues",
+		},
+		{
+			name: "done issues are not part of the chain",
+			issues: []Issue{
+				newIssue("A-1", "Done", "A-2"),
+				newIssue("A-2", "To Do", "A-3"),
+				newIssue("A-3", "To Do"),
+				newIssue("A-4", "To Do"),
+			},
+			want:     "[A-2 A-3] 2",
+			wantUnit: "issues",
+		},
+		{
+			name: "story points beat count",
+			issues: []Issue{
+				withPoints(newIssue("A-1", "To Do", "A-2"), "1"),
+				withPoints(newIssue("A-2", "To Do", "A-3"), "1"),
+				withPoints(newIssue("A-3", "To Do"), "1"),
+				withPoints(newIssue("B-1", "To Do", "B-2"), "5"),
+				newIssue("B-2", "To Do"),
+			},
+			lut:      points,
+			want:     "[B-1 B-2] 7",
+			wantUnit: "story points",
+		},
+		{
+			name: "issues without story points weigh the mean",
+			issues: append(chain("A", 15),
+				withPoints(newIssue("B-1", "To Do"), "1"),
+				withPoints(newIssue("B-2", "To Do"), "3")),
+			lut:      points,
+			want:     "[A-1 A-2 A-3 A-4 A-5 A-6 A-7 A-8 A-9 A-10 A-11 A-12 A-13 A-14 A-15] 30",
+			wantUnit: "story points",
+		},
+		{
+			name: "zero story points are an estimate",
+			issues: []Issue{
+				withPoints(newIssue("A-1", "To Do", "A-2"), "0"),
+				withPoints(newIssue("A-2", "To Do", "A-3"), "0"),
+				withPoints(newIssue("A-3", "To Do"), "0"),
+				withPoints(newIssue("B-1", "To Do"), "2"),
+			},
+			lut:      points,
+			want:     "[B-1] 2",
+			wantUnit: "story points",
+		},
+		{
+			name: "story points without the custom field fall back to count",
+			issues: []Issue{
+				withPoints(newIssue("B-1", "To Do", "B-2"), "5"),
+				newIssue("B-2", "To Do"),
+			},
+			want:     "[B-1 B-2] 2",
+			wantUnit: "issues",
+		},
+		{
+			name: "original estimate",
+			issues: []Issue{
+				withEstimate(newIssue("A-1", "To Do", "A-3"), 8),
+				withEstimate(newIssue("A-2", "To Do", "A-3"), 2),
+				withEstimate(newIssue("A-3", "To Do"), 4),
+			},
+			want:     "[A-1 A-3] 12",
+			wantUnit: "hours",
+		},
+		{
+			name: "zero original estimate is an estimate",
+			issues: []Issue{
+				withEstimate(newIssue("A-1", "To Do", "A-2"), 0),
+				withEstimate(newIssue("A-2", "To Do"), 0),
+				withEstimate(newIssue("B-1", "To Do"), 3),
+			},
+			want:     "[B-1] 3",
+			wantUnit: "hours",
+		},
+		{
+			name: "placeholders are not part of the chain",
+			issues: []Issue{
+				newIssue("A-1", "To Do", "X-1"),
+			},
+			want:     "[A-1] 1",
+			wantUnit: "issues",
+		},
+	}
+
+	test := func(t *testing.T, tc testCase) {
+		g := NewGraph()
+		for _, ticket := range tc.issues {
+			g.AddIssue(ticket)
+		}
+
+		wgt := chooseWeight(g.Issues(), tc.lut)
+		path, total, err := findCriticalPath(g, dependencyLinkTypes, wgt)
+
+		rosina.AssertNoError(t, err)
+		rosina.AssertEqual(t, fmt.Sprint(path, " ", total), tc.want, "critical path")
+		rosina.AssertEqual(t, wgt.unit, tc.wantUnit, "unit")
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) { test(t, tc) })
+	}
+}
+
+func TestFindCriticalPathCycles(t *testing.T) {
+	g := NewGraph()
+	g.AddIssue(newIssue("A-1", "To Do", "A-2"))
+	g.AddIssue(newIssue("A-2", "To Do", "A-1"))
+
+	_, _, err := findCriticalPath(g, dependencyLinkTypes, chooseWeight(nil, nil))
+
+	rosina.AssertEqual(t, err, errCycles, "error")
+}
+
+func TestCriticalEdgesAndPrint(t *testing.T) {
+	g := NewGraph()
+	g.AddIssue(newIssue("A-1", "To Do", "A-2", "A-3"))
+	g.AddIssue(newIssue("A-2", "To Do", "A-3"))
+	g.AddIssue(newIssue("A-3", "To Do"))
+
+	wgt := chooseWeight(g.Issues(), nil)
+	path, total, err := findCriticalPath(g, dependencyLinkTypes, wgt)
+	rosina.AssertNoError(t, err)
+
+	edges := criticalEdges(g, path, dependencyLinkTypes)
+	var names []string
+	for _, edge := range g.Edges() {
+		if edges[edge] {
+			names = append(names, edge.From+"->"+edge.To)
+		}
+	}
+	rosina.AssertEqual(t, strings.Join(names, " "), "A-1->A-2 A-2->A-3",
+		"critical edges")
+
+	var bld strings.Builder
+	printCriticalPath(&bld, g, path, total, wgt)
+	want := `critical path: 3 issues
+  A-1 summary of A-1
+  A-2 summary of A-2
+  A-3 summary of A-3
+`
+	rosina.AssertEqual(t, bld.String(), want, "printCriticalPath")
+
+	bld.Reset()
+	err = dotRenderer{rankdir: "LR", criticalEdges: edges}.Render(&bld, g)
+	rosina.AssertNoError(t, err)
+	rosina.AssertEqual(t,
+		strings.Contains(bld.String(), `"A-1" -> "A-2" [label="blocks" color="red" penwidth="6"]`),
+		true, "critical edge is thick")
+}
+
+func TestPrintCriticalPathPartialEstimates(t *testing.T) {
+	withEstimate := func(ticket Issue, hours int) Issue {
+		ticket.Fields.TimeTracking.OriginalEstimate = fmt.Sprintf("%dh", hours)
+		ticket.Fields.TimeTracking.OriginalEstimateSeconds = hours * 3600
+		return ticket
+	}
+	g := NewGraph()
+	g.AddIssue(withEstimate(newIssue("A-1", "To Do", "A-2"), 2))
+	g.AddIssue(newIssue("A-2", "To Do", "A-3"))
+	g.AddIssue(withEstimate(newIssue("A-3", "To Do"), 3))
+	g.AddIssue(withEstimate(newIssue("B-1", "Done"), 5))
+
+	wgt := chooseWeight(g.Issues(), nil)
+	path, total, err := findCriticalPath(g, dependencyLinkTypes, wgt)
+	rosina.AssertNoError(t, err)
+
+	var bld strings.Builder
+	printCriticalPath(&bld, g, path, total, wgt)
+	// The mean ignores B-1, since it is done.
+	want := `critical path: 3 issues, 7.5 hours
+  A-1 (2) summary of A-1
+  A-2 (~2.5) summary of A-2
+  A-3 (3) summary of A-3
+`
+	rosina.AssertEqual(t, bld.String(), want, "printCriticalPath")
+}
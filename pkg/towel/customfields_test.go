@@ -1,71 +1,13 @@
 package towel_test
 
 import (
-	"encoding/json"
+	"fmt"
 	"testing"
 
 	"github.com/marco-m/jira-towel/pkg/towel"
 	"github.com/marco-m/rosina"
-	"github.com/mitchellh/mapstructure"
 )
 
-// The problem is that Jira, in the JSON response, mixes well-known fields
-// (the fields declared by the API, always available) with numeric custom
-// fields of the form "customfield_11919", AT THE SAME LEVEL. For example:
-//
-//	"fields": {
-//	  "customfield_12011": ...
-//	  "labels": [ ... ]
-//	  "assignee": ...
-//	}
-//
-// It would have been enough to simply put all the custom fields in a dedicated
-// subobject, but unfortunately this is not how Jira does it:
-//
-//	"fields": {
-//	  "labels": [ ... ]
-//	  "assignee": ...
-//	}
-//	"customfields": {            <== WOULD HAVE BEEN SO EASY :-(
-//	  "customfield_12011": ...
-//	}
-//
-// So, we use the 'mapstructure' package to avoid as much as possible to copy
-// fields by hand.
-//
-// 'mapstructure' looks for keys in JSON with the same name as the fields in
-// the struct, but the comparison is case insensitive.
-type Wrong struct {
-	Reasonable int
-	Normal     string
-	// Tag 'remain' tells 'mapstructure' to collect all unknown fields, at any
-	// level of nesting.
-	Absurds map[string]any `mapstructure:",remain"`
-}
-
-func TestMapstructureCanHelp(t *testing.T) {
-	inputJson := `{
-  "reasonable":        42,
-  "normal":            "i am normal",
-  "customfield_1234":  {"value": "i am product X"},
-  "customfield_3452":  {"value": "i am absurd 2"}
-}`
-
-	var parsedMap map[string]any
-	err := json.Unmarshal([]byte(inputJson), &parsedMap)
-	rosina.AssertNoError(t, err)
-
-	// Only partial mapping, as happens in real Jira replies.
-	lut := map[string]int{"product": 1234}
-	var result Wrong
-	err = mapstructure.Decode(parsedMap, &result)
-	rosina.AssertNoError(t, err)
-
-	have := towel.CustomfieldValue(result.Absurds, lut, "product")
-	want := "i am product X"
-	rosina.AssertEqual(t, have, want, "customfield existing")
-}
-
 func TestCustomFieldValue(t *testing.T) {
 	lut := map[string]int{
 		"product":  1234,
@@ -73,11 +15,11 @@ func TestCustomFieldValue(t *testing.T) {
 		"broken-1": 99,
 		"broken-2": 34,
 	}
-	customfields := map[string]any{
-		"customfield_1234": map[string]any{"value": "i am product X"},
-		"customfield_3452": map[string]any{"value": "i am feature 2"},
-		"customfield_99":   map[string]any{"broken": "i am broken"},
-		"customfield_34":   "I am broken also",
+	customfields := map[string]towel.CustomField{
+		"customfield_1234": towel.CustomField(`{"value": "i am product X"}`),
+		"customfield_3452": towel.CustomField(`{"value": "i am feature 2"}`),
+		"customfield_99":   towel.CustomField(`{"broken": "i am broken"}`),
+		"customfield_34":   towel.CustomField(`"I am broken also"`),
 	}
 
 	have := towel.CustomfieldValue(customfields, lut, "product")
@@ -100,3 +42,40 @@ func TestCustomFieldValue(t *testing.T) {
 	want = ""
 	rosina.AssertEqual(t, have, want, "customfield broken-2")
 }
+
+func TestCustomFieldValues(t *testing.T) {
+	type testCase struct {
+		name string
+		cf   towel.CustomField
+		want string
+	}
+
+	testCases := []testCase{
+		{name: "absent", cf: nil, want: "[]"},
+		{name: "null", cf: towel.CustomField(`null`), want: "[]"},
+		{name: "string", cf: towel.CustomField(`"MANGO-1"`), want: "[MANGO-1]"},
+		{name: "option", cf: towel.CustomField(`{"value": "X"}`), want: "[X]"},
+		{
+			name: "multi-select",
+			cf:   towel.CustomField(`[{"value": "X"}, {"value": "Y"}]`),
+			want: "[X Y]",
+		},
+		{
+			name: "sprints",
+			cf:   towel.CustomField(`[{"id": 12, "name": "Sprint 7"}]`),
+			want: "[Sprint 7]",
+		},
+		{name: "number", cf: towel.CustomField(`3`), want: "[]"},
+		{name: "unknown object", cf: towel.CustomField(`{"broken": "X"}`), want: "[]"},
+	}
+
+	test := func(t *testing.T, tc testCase) {
+		have := fmt.Sprint(tc.cf.Values())
+
+		rosina.AssertEqual(t, have, tc.want, "values")
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) { test(t, tc) })
+	}
+}
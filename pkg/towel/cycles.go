@@ -0,0 +1,113 @@
+package towel
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// findCycles returns the dependency cycles of 'g', considering only the edges
+// of 'linkTypes' (all if empty; see matchLinkType).
+//
+// Each cycle is a strongly connected component of the graph: a set of issues
+// where each issue can reach all the others (a component can then contain
+// more than one elementary cycle). Each cycle is sorted by key, and the cycles
+// are sorted by their first key.
+func findCycles(g *Graph, linkTypes []string) [][]string {
+	// Tarjan's strongly connected components algorithm.
+	// https://en.wikipedia.org/wiki/Tarjan%27s_strongly_connected_components_algorithm
+	index := 0
+	indices := make(map[string]int)
+	lowlinks := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+	var cycles [][]string
+
+	var strongConnect func(key string)
+	strongConnect = func(key string) {
+		indices[key] = index
+		lowlinks[key] = index
+		index++
+		stack = append(stack, key)
+		onStack[key] = true
+
+		selfLoop := false
+		for _, edge := range g.OutEdges(key) {
+			if !matchLinkType(edge.Type, linkTypes) {
+				continue
+			}
+			if edge.To == key {
+				selfLoop = true
+			}
+			if _, visited := indices[edge.To]; !visited {
+				strongConnect(edge.To)
+				lowlinks[key] = min(lowlinks[key], lowlinks[edge.To])
+			} else if onStack[edge.To] {
+				lowlinks[key] = min(lowlinks[key], indices[edge.To])
+			}
+		}
+
+		if lowlinks[key] != indices[key] {
+			return
+		}
+		var component []string
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component = append(component, top)
+			if top == key {
+				break
+			}
+		}
+		if len(component) > 1 || selfLoop {
+			slices.SortFunc(component, compareKeys)
+			cycles = append(cycles, component)
+		}
+	}
+
+	for _, node := range g.Nodes() {
+		if _, visited := indices[node.Key]; !visited {
+			strongConnect(node.Key)
+		}
+	}
+
+	slices.SortFunc(cycles, func(a, b []string) int {
+		return cmp.Or(compareKeys(a[0], b[0]), cmp.Compare(len(a), len(b)))
+	})
+	return cycles
+}
+
+// cycleEdges returns the edges of 'g' that belong to one of 'cycles', as
+// returned by findCycles with the same 'linkTypes'.
+func cycleEdges(g *Graph, cycles [][]string, linkTypes []string) map[*Edge]bool {
+	component := make(map[string]int)
+	for i, cycle := range cycles {
+		for _, key := range cycle {
+			component[key] = i + 1
+		}
+	}
+	edges := make(map[*Edge]bool)
+	for _, edge := range g.Edges() {
+		if !matchLinkType(edge.Type, linkTypes) {
+			continue
+		}
+		from, to := component[edge.From], component[edge.To]
+		if from != 0 && from == to {
+			edges[edge] = true
+		}
+	}
+	return edges
+}
+
+// printCycles prints 'cycles' in human-readable form.
+func printCycles(w io.Writer, g *Graph, cycles [][]string) {
+	for i, cycle := range cycles {
+		fmt.Fprintf(w, "cycle %d (%d issues):\n", i+1, len(cycle))
+		for _, key := range cycle {
+			node, _ := g.Node(key)
+			fmt.Fprintf(w, "  %s %s\n", key, node.Issue.Fields.Summary)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package towel
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/marco-m/rosina"
+)
+
+func TestFindCycles(t *testing.T) {
+	type testCase struct {
+		name      string
+		issues    []Issue
+		linkTypes []string
+		want      string
+	}
+
+	relates := LinkType{Name: "Relates", Inward: "relates to", Outward: "relates to"}
+	relatesTo := func(src, dst string) Issue {
+		ticket := newIssue(src, "To Do")
+		ticket.Fields.Issuelinks = []IssueLink{
+			{Type: relates, OutwardIssue: Issue{Key: dst}},
+		}
+		return ticket
+	}
+
+	testCases := []testCase{
+		{
+			name: "no cycles",
+			issues: []Issue{
+				newIssue("A-1", "To Do", "A-2", "A-3"),
+				newIssue("A-2", "To Do", "A-3"),
+			},
+			want: "[]",
+		},
+		{
+			name: "two issues",
+			issues: []Issue{
+				newIssue("A-1", "To Do", "A-2"),
+				newIssue("A-2", "To Do", "A-1"),
+			},
+			want: "[[A-1 A-2]]",
+		},
+		{
+			name: "three issues with a tail",
+			issues: []Issue{
+				newIssue("A-1", "To Do", "A-2"),
+				newIssue("A-2", "To Do", "A-10"),
+				newIssue("A-10", "To Do", "A-3"),
+				newIssue("A-3", "To Do", "A-2"),
+			},
+			want: "[[A-2 A-3 A-10]]",
+		},
+		{
+			name: "two cycles",
+			issues: []Issue{
+				newIssue("B-1", "To Do", "B-2"),
+				newIssue("B-2", "To Do", "B-1", "A-1"),
+				newIssue("A-1", "To Do", "A-2"),
+				newIssue("A-2", "To Do", "A-1"),
+			},
+			want: "[[A-1 A-2] [B-1 B-2]]",
+		},
+		{
+			name:   "self loop",
+			issues: []Issue{newIssue("A-1", "To Do", "A-1")},
+			want:   "[[A-1]]",
+		},
+		{
+			name: "other link types are ignored",
+			issues: []Issue{
+				newIssue("A-1", "To Do", "A-2"),
+				relatesTo("A-2", "A-1"),
+			},
+			linkTypes: []string{"blocks"},
+			want:      "[]",
+		},
+		{
+			name: "all link types",
+			issues: []Issue{
+				newIssue("A-1", "To Do", "A-2"),
+				relatesTo("A-2", "A-1"),
+			},
+			want: "[[A-1 A-2]]",
+		},
+	}
+
+	test := func(t *testing.T, tc testCase) {
+		g := NewGraph()
+		for _, ticket := range tc.issues {
+			g.AddIssue(ticket)
+		}
+
+		have := findCycles(g, tc.linkTypes)
+
+		rosina.AssertEqual(t, fmt.Sprint(have), tc.want, "cycles")
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) { test(t, tc) })
+	}
+}
+
+func TestCycleEdgesAndPrint(t *testing.T) {
+	g := NewGraph()
+	g.AddIssue(newIssue("A-1", "To Do", "A-2"))
+	g.AddIssue(newIssue("A-2", "To Do", "A-3"))
+	g.AddIssue(newIssue("A-3", "To Do", "A-2"))
+
+	cycles := findCycles(g, nil)
+	edges := cycleEdges(g, cycles, nil)
+
+	rosina.AssertEqual(t, len(edges), 2, "edges in cycles")
+	for edge := range edges {
+		rosina.AssertEqual(t, edge.From != "A-1", true, "A-1 is not in the cycle")
+	}
+
+	var bld strings.Builder
+	printCycles(&bld, g, cycles)
+	want := `cycle 1 (2 issues):
+  A-2 summary of A-2
+  A-3 summary of A-3
+`
+	rosina.AssertEqual(t, bld.String(), want, "printCycles")
+}
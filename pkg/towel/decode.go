@@ -0,0 +1,242 @@
+package towel
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// CustomField is the raw JSON value of a Jira custom field. It is kept as raw
+// JSON because its shape depends on the type of the custom field (option,
+// string, number, array, ...); use the methods to interpret it.
+type CustomField json.RawMessage
+
+// Value returns the "value" of a custom field of type option, or the empty
+// string if the custom field has a different shape. A custom field of type
+// option has the following shape:
+//
+//	"customfield_11919": {
+//	    "self": "https://x.atlassian.net/rest/api/2/customFieldOption/10837",
+//	    "value": "Foo Bar", <=== THIS
+//	    "id": "10837"
+//	}
+func (cf CustomField) Value() string {
+	var option struct {
+		Value *string `json:"value"`
+	}
+	if err := json.Unmarshal(cf, &option); err != nil || option.Value == nil {
+		return ""
+	}
+	return *option.Value
+}
+
+// CustomfieldValue returns the value of custom field 'name' from map
+// 'customFields', as filled by the decoder of the issue fields.
+// See the tests in customfields_test for an example.
+// If 'name' is not present, CustomfieldValue returns the empty string.
+// CustomfieldValue assumes that lookup table 'lut' is filled by manual inspection
+// of the JSON object returned by Jira.
+// Yes, this sucks.
+func CustomfieldValue(customFields map[string]CustomField, lut map[string]int, name string) string {
+	return lookupCustomField(customFields, lut, name).Value()
+}
+
+// lookupCustomField returns custom field 'name' from map 'customFields', or
+// nil if not present. See CustomfieldValue.
+func lookupCustomField(customFields map[string]CustomField, lut map[string]int, name string) CustomField {
+	id, found := lut[name]
+	if !found {
+		return nil
+	}
+	return customFields[fmt.Sprintf("customfield_%d", id)]
+}
+
+// Text returns the value of a custom field of type string (for example the
+// rank), or the empty string if the custom field has a different shape.
+func (cf CustomField) Text() string {
+	var text string
+	if err := json.Unmarshal(cf, &text); err != nil {
+		return ""
+	}
+	return text
+}
+
+// Values returns the values of a custom field, for any of the common shapes:
+// a string, an option object (see Value), an object with a name (for example
+// a sprint), or an array of these (for example a multi-select). Other shapes
+// give no values.
+func (cf CustomField) Values() []string {
+	var elems []json.RawMessage
+	if err := json.Unmarshal(cf, &elems); err != nil {
+		elems = []json.RawMessage{json.RawMessage(cf)}
+	}
+	var values []string
+	for _, elem := range elems {
+		var object struct {
+			Value *string `json:"value"`
+			Name  *string `json:"name"`
+		}
+		var value string
+		if json.Unmarshal(elem, &value) != nil && json.Unmarshal(elem, &object) == nil {
+			switch {
+			case object.Value != nil:
+				value = *object.Value
+			case object.Name != nil:
+				value = *object.Name
+			}
+		}
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// Number returns the value of a custom field of type number (for example
+// story points), or false if the custom field has a different shape.
+func (cf CustomField) Number() (float64, bool) {
+	var number *float64
+	if err := json.Unmarshal(cf, &number); err != nil || number == nil {
+		return 0, false
+	}
+	return *number, true
+}
+
+// The problem is that Jira, in the JSON response, mixes well-known fields
+// (the fields declared by the API, always available) with numeric custom
+// fields of the form "customfield_11919", AT THE SAME LEVEL. For example:
+//
+//	"fields": {
+//	  "customfield_12011": ...
+//	  "labels": [ ... ]
+//	  "assignee": ...
+//	}
+//
+// It would have been enough to simply put all the custom fields in a dedicated
+// subobject, but unfortunately this is not how Jira does it:
+//
+//	"fields": {
+//	  "labels": [ ... ]
+//	  "assignee": ...
+//	}
+//	"customfields": {            <== WOULD HAVE BEEN SO EASY :-(
+//	  "customfield_12011": ...
+//	}
+//
+// So UnmarshalJSON walks the object key by key, decoding each well-known
+// field directly into its typed struct field and routing each custom field
+// into CustomFields, in one pass. Unknown fields are skipped.
+func (f *Fields) UnmarshalJSON(data []byte) error {
+	*f = Fields{}
+	if string(bytes.TrimSpace(data)) == "null" {
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if err := expectDelim(dec, '{'); err != nil {
+		return fmt.Errorf("fields: %s", err)
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("fields: %s", err)
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return fmt.Errorf("fields: unexpected key %v", tok)
+		}
+
+		if strings.HasPrefix(key, "customfield_") {
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				return fmt.Errorf("fields: %s: %s", key, err)
+			}
+			if f.CustomFields == nil {
+				f.CustomFields = make(map[string]CustomField)
+			}
+			f.CustomFields[key] = CustomField(raw)
+			continue
+		}
+
+		target := f.wellKnown(key)
+		if target == nil {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return fmt.Errorf("fields: %s: %s", key, err)
+			}
+			continue
+		}
+		if err := dec.Decode(target); err != nil {
+			return fmt.Errorf("fields: %s: %s", key, err)
+		}
+	}
+	if err := expectDelim(dec, '}'); err != nil {
+		return fmt.Errorf("fields: %s", err)
+	}
+	return nil
+}
+
+// wellKnown returns a pointer to the struct field corresponding to JSON key
+// 'key', or nil if 'key' is not a field we care about.
+func (f *Fields) wellKnown(key string) any {
+	switch key {
+	case "issuetype":
+		return &f.IssueType
+	case "parent":
+		return &f.Parent
+	case "project":
+		return &f.Project
+	case "priority":
+		return &f.Priority
+	case "labels":
+		return &f.Labels
+	case "issuelinks":
+		return &f.Issuelinks
+	case "assignee":
+		return &f.Assignee
+	case "reporter":
+		return &f.Reporter
+	case "creator":
+		return &f.Creator
+	case "status":
+		return &f.Status
+	case "resolution":
+		return &f.Resolution
+	case "description":
+		return &f.Description
+	case "summary":
+		return &f.Summary
+	case "subtasks":
+		return &f.Subtasks
+	case "components":
+		return &f.Components
+	case "fixVersions":
+		return &f.FixVersions
+	case "duedate":
+		return &f.Duedate
+	case "created":
+		return &f.Created
+	case "updated":
+		return &f.Updated
+	case "resolutiondate":
+		return &f.ResolutionDate
+	case "timetracking":
+		return &f.TimeTracking
+	case "progress":
+		return &f.Progress
+	default:
+		return nil
+	}
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != want {
+		return fmt.Errorf("have: %v; want: %v", tok, want)
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package towel
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/marco-m/rosina"
+)
+
+func TestDecodeFieldsRoutesCustomFields(t *testing.T) {
+	input := `{
+  "summary":           "i am normal",
+  "labels":            ["a", "b"],
+  "customfield_1234":  {"value": "i am product X"},
+  "customfield_3452":  {"value": "i am absurd 2"},
+  "customfield_10016": 3.0,
+  "unknown":           {"nested": {"customfield_9": "not at top level"}}
+}`
+
+	var have Fields
+	err := json.Unmarshal([]byte(input), &have)
+	rosina.AssertNoError(t, err)
+
+	rosina.AssertEqual(t, have.Summary, "i am normal", "summary")
+	rosina.AssertEqual(t, len(have.Labels), 2, "labels")
+	rosina.AssertEqual(t, len(have.CustomFields), 3, "number of custom fields")
+	rosina.AssertEqual(t, have.CustomFields["customfield_1234"].Value(),
+		"i am product X", "customfield_1234")
+	rosina.AssertEqual(t, string(have.CustomFields["customfield_10016"]),
+		"3.0", "customfield_10016")
+}
+
+func TestDecodeFieldsNull(t *testing.T) {
+	var have Issue
+	err := json.Unmarshal([]byte(`{"key": "MANGO-1", "fields": null}`), &have)
+	rosina.AssertNoError(t, err)
+
+	rosina.AssertEqual(t, have.Key, "MANGO-1", "key")
+	rosina.AssertEqual(t, len(have.Fields.CustomFields), 0, "custom fields")
+}
+
+func TestDecodeFieldsReportsErrors(t *testing.T) {
+	var have Fields
+	err := json.Unmarshal([]byte(`{"summary": 42}`), &have)
+
+	if err == nil {
+		t.Fatalf("have: <no error>; want: an error")
+	}
+	rosina.AssertEqual(t, strings.Contains(err.Error(), "summary"), true,
+		"error mentions the field")
+}
+
+func TestDecodeRecordedPage(t *testing.T) {
+	raw, err := os.ReadFile("testdata/search-page.json")
+	rosina.AssertNoError(t, err)
+
+	issues, err := decodePage(raw)
+	rosina.AssertNoError(t, err)
+
+	rosina.AssertEqual(t, len(issues), 3, "number of issues")
+	epic := issues[0]
+	rosina.AssertEqual(t, epic.Key, "MANGO-1", "key")
+	rosina.AssertEqual(t, epic.Fields.IssueType.Name, "Epic", "issue type")
+	rosina.AssertEqual(t, epic.Fields.Status.Name, "In Progress", "status")
+	rosina.AssertEqual(t, epic.Fields.Status.StatusCategory.Key, "indeterminate",
+		"status category")
+	rosina.AssertEqual(t, epic.Fields.Summary, "Prepare the mango", "summary")
+	rosina.AssertEqual(t,
+		CustomfieldValue(epic.Fields.CustomFields, map[string]int{"product": 11919}, "product"),
+		"Continuous Deployment", "custom field")
+	rosina.AssertEqual(t, epic.Fields.Assignee.DisplayName, "Alice Liddell", "assignee")
+	rosina.AssertEqual(t, epic.Fields.Assignee.EmailAddress, "alice@example.com", "email")
+	rosina.AssertEqual(t, epic.Fields.Duedate.String(), "2024-10-15", "due date")
+	rosina.AssertEqual(t, epic.Fields.Labels[1], "q4", "label")
+	rosina.AssertEqual(t, epic.Fields.Components[0].Name, "Kitchen", "component")
+	rosina.AssertEqual(t, epic.Fields.FixVersions[0].Name, "2024.10", "fix version")
+	rosina.AssertEqual(t, epic.Fields.FixVersions[0].ReleaseDate.String(),
+		"2024-10-31", "fix version release date")
+	rosina.AssertEqual(t, epic.Fields.Resolution == nil, true, "resolution")
+	rosina.AssertEqual(t, epic.Fields.ResolutionDate.IsZero(), true, "resolution date")
+
+	story := issues[1]
+	rosina.AssertEqual(t, story.Fields.Parent.Key, "MANGO-1", "parent")
+	rosina.AssertEqual(t, story.Fields.Issuelinks[0].OutwardIssue.Key, "MANGO-3",
+		"outward link")
+	rosina.AssertEqual(t,
+		story.Fields.Issuelinks[0].OutwardIssue.Fields.Status.StatusCategory.Key, "new",
+		"status category of the linked issue")
+	rosina.AssertEqual(t, story.Fields.Assignee == nil, true, "unassigned")
+	rosina.AssertEqual(t, story.Fields.Duedate.IsZero(), true, "no due date")
+	rosina.AssertEqual(t, story.Fields.Subtasks[0].Key, "MANGO-4", "subtask")
+	rosina.AssertEqual(t, story.Fields.Subtasks[0].Fields.IssueType.Subtask, true,
+		"subtask type")
+	rosina.AssertEqual(t, story.Fields.TimeTracking.OriginalEstimateSeconds, 7200,
+		"original estimate")
+	rosina.AssertEqual(t, story.Fields.TimeTracking.TimeSpent, "2h 30m", "time spent")
+	rosina.AssertEqual(t, story.Fields.Created.String(),
+		"2024-09-02T09:30:00.000+0200", "created")
+}
+
+// recordedFields returns the "fields" objects of all the issues in the recorded
+// payloads in testdata.
+func recordedFields(t testing.TB) [][]byte {
+	paths, err := filepath.Glob("testdata/*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var result [][]byte
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dec := json.NewDecoder(file)
+		for dec.More() {
+			var page struct {
+				Issues []struct {
+					Fields json.RawMessage `json:"fields"`
+				} `json:"issues"`
+			}
+			if err := dec.Decode(&page); err != nil {
+				t.Fatalf("%s: %s", path, err)
+			}
+			for _, ticket := range page.Issues {
+				result = append(result, ticket.Fields)
+			}
+		}
+		file.Close() // nolint:errcheck
+	}
+	return result
+}
+
+func FuzzDecodeFields(f *testing.F) {
+	for _, seed := range recordedFields(f) {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var have Fields
+		if err := json.Unmarshal(data, &have); err != nil {
+			return
+		}
+		// Whatever the decoder accepted, it must agree with a generic decoder
+		// on the set of custom fields.
+		var generic map[string]json.RawMessage
+		if err := json.Unmarshal(data, &generic); err != nil {
+			return
+		}
+		want := 0
+		for key := range generic {
+			if strings.HasPrefix(key, "customfield_") {
+				want++
+				if _, found := have.CustomFields[key]; !found {
+					t.Fatalf("custom field %s: not found", key)
+				}
+			}
+		}
+		rosina.AssertEqual(t, len(have.CustomFields), want, "number of custom fields")
+	})
+}
+
+func BenchmarkDecodePage(b *testing.B) {
+	recorded, err := os.ReadFile("testdata/search-page.json")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	pages := []struct {
+		name string
+		raw  []byte
+	}{
+		{name: "recorded", raw: recorded},
+		{name: "synthetic-100", raw: []byte(fakeSearchPage(0, 100, 100))},
+	}
+
+	for _, page := range pages {
+		b.Run(page.name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(page.raw)))
+			for range b.N {
+				if _, err := decodePage(page.raw); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package towel
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// snapshotDiff contains the differences between two snapshots. All the lists
+// are sorted by issue key, so that the output is stable.
+type snapshotDiff struct {
+	Added           []issueRef    `json:"added"`
+	Removed         []issueRef    `json:"removed"`
+	StatusChanges   []fieldChange `json:"status_changes"`
+	SummaryChanges  []fieldChange `json:"summary_changes"`
+	AssigneeChanges []fieldChange `json:"assignee_changes"`
+	LinksAdded      []linkRef     `json:"links_added"`
+	LinksRemoved    []linkRef     `json:"links_removed"`
+}
+
+type issueRef struct {
+	Key     string `json:"key"`
+	Summary string `json:"summary"`
+}
+
+type fieldChange struct {
+	Key  string `json:"key"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// linkRef is a link between two issues, always expressed in the outward
+// direction (for example: From "blocks" To).
+type linkRef struct {
+	From     string `json:"from"`
+	Relation string `json:"relation"`
+	To       string `json:"to"`
+}
+
+func (d snapshotDiff) empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 &&
+		len(d.StatusChanges) == 0 && len(d.SummaryChanges) == 0 &&
+		len(d.AssigneeChanges) == 0 &&
+		len(d.LinksAdded) == 0 && len(d.LinksRemoved) == 0
+}
+
+// diffIssues returns the differences going from 'oldIssues' to 'newIssues'.
+func diffIssues(oldIssues, newIssues []Issue) snapshotDiff {
+	oldByKey := make(map[string]Issue, len(oldIssues))
+	for _, ticket := range oldIssues {
+		oldByKey[ticket.Key] = ticket
+	}
+	newByKey := make(map[string]Issue, len(newIssues))
+	for _, ticket := range newIssues {
+		newByKey[ticket.Key] = ticket
+	}
+
+	var diff snapshotDiff
+	for key, newTicket := range newByKey {
+		oldTicket, found := oldByKey[key]
+		if !found {
+			diff.Added = append(diff.Added,
+				issueRef{Key: key, Summary: newTicket.Fields.Summary})
+			continue
+		}
+		if change, ok := compareField(key, oldTicket.Fields.Status.Name,
+			newTicket.Fields.Status.Name); ok {
+			diff.StatusChanges = append(diff.StatusChanges, change)
+		}
+		if change, ok := compareField(key, oldTicket.Fields.Summary,
+			newTicket.Fields.Summary); ok {
+			diff.SummaryChanges = append(diff.SummaryChanges, change)
+		}
+		if change, ok := compareField(key, assigneeName(oldTicket),
+			assigneeName(newTicket)); ok {
+			diff.AssigneeChanges = append(diff.AssigneeChanges, change)
+		}
+	}
+	for key, oldTicket := range oldByKey {
+		if _, found := newByKey[key]; !found {
+			diff.Removed = append(diff.Removed,
+				issueRef{Key: key, Summary: oldTicket.Fields.Summary})
+		}
+	}
+
+	oldLinks := collectLinks(oldIssues)
+	newLinks := collectLinks(newIssues)
+	for link := range newLinks {
+		if _, found := oldLinks[link]; !found {
+			diff.LinksAdded = append(diff.LinksAdded, link)
+		}
+	}
+	for link := range oldLinks {
+		if _, found := newLinks[link]; !found {
+			diff.LinksRemoved = append(diff.LinksRemoved, link)
+		}
+	}
+
+	sortRefs := func(a, b issueRef) int { return compareKeys(a.Key, b.Key) }
+	slices.SortFunc(diff.Added, sortRefs)
+	slices.SortFunc(diff.Removed, sortRefs)
+	sortChanges := func(a, b fieldChange) int { return compareKeys(a.Key, b.Key) }
+	slices.SortFunc(diff.StatusChanges, sortChanges)
+	slices.SortFunc(diff.SummaryChanges, sortChanges)
+	slices.SortFunc(diff.AssigneeChanges, sortChanges)
+	sortLinks := func(a, b linkRef) int {
+		return cmp.Or(
+			compareKeys(a.From, b.From),
+			compareKeys(a.To, b.To),
+			cmp.Compare(a.Relation, b.Relation))
+	}
+	slices.SortFunc(diff.LinksAdded, sortLinks)
+	slices.SortFunc(diff.LinksRemoved, sortLinks)
+
+	return diff
+}
+
+func compareField(key, from, to string) (fieldChange, bool) {
+	if from == to {
+		return fieldChange{}, false
+	}
+	return fieldChange{Key: key, From: from, To: to}, true
+}
+
+// collectLinks returns the set of links of 'issues'. Since Jira reports the
+// same link on both issues (outward on one, inward on the other), each link
+// is normalized to the outward direction, so that it is counted only once.
+func collectLinks(issues []Issue) map[linkRef]struct{} {
+	links := make(map[linkRef]struct{})
+	for _, ticket := range issues {
+		for _, link := range ticket.Fields.Issuelinks {
+			if link.OutwardIssue.Key != "" {
+				links[linkRef{
+					From:     ticket.Key,
+					Relation: link.Type.Outward,
+					To:       link.OutwardIssue.Key,
+				}] = struct{}{}
+			}
+			if link.InwardIssue.Key != "" {
+				links[linkRef{
+					From:     link.InwardIssue.Key,
+					Relation: link.Type.Outward,
+					To:       ticket.Key,
+				}] = struct{}{}
+			}
+		}
+	}
+	return links
+}
+
+// assigneeName returns the display name of the assignee of 'ticket', or the
+// empty string if the issue is unassigned.
+func assigneeName(ticket Issue) string {
+	if ticket.Fields.Assignee == nil {
+		return ""
+	}
+	return ticket.Fields.Assignee.DisplayName
+}
+
+// compareKeys compares two Jira issue keys, such as "MANGO-9" and "MANGO-10",
+// first by project and then numerically by issue number, so that MANGO-9 comes
+// before MANGO-10. Malformed keys are compared as strings.
+func compareKeys(a, b string) int {
+	projA, numA, okA := splitKey(a)
+	projB, numB, okB := splitKey(b)
+	if !okA || !okB {
+		return cmp.Compare(a, b)
+	}
+	return cmp.Or(cmp.Compare(projA, projB), cmp.Compare(numA, numB))
+}
+
+func splitKey(key string) (string, int, bool) {
+	idx := strings.LastIndexByte(key, '-')
+	if idx < 0 {
+		return "", 0, false
+	}
+	num, err := strconv.Atoi(key[idx+1:])
+	if err != nil {
+		return "", 0, false
+	}
+	return key[:idx], num, true
+}
+
+// writeText writes 'd' in human-readable form.
+func (d snapshotDiff) writeText(w io.Writer) {
+	if d.empty() {
+		fmt.Fprintln(w, "no changes")
+		return
+	}
+	if len(d.Added) > 0 {
+		fmt.Fprintln(w, "added issues:")
+		for _, ref := range d.Added {
+			fmt.Fprintf(w, "  %s %s\n", ref.Key, ref.Summary)
+		}
+	}
+	if len(d.Removed) > 0 {
+		fmt.Fprintln(w, "removed issues:")
+		for _, ref := range d.Removed {
+			fmt.Fprintf(w, "  %s %s\n", ref.Key, ref.Summary)
+		}
+	}
+	writeChanges(w, "status changes:", d.StatusChanges)
+	writeChanges(w, "summary changes:", d.SummaryChanges)
+	writeChanges(w, "assignee changes:", d.AssigneeChanges)
+	if len(d.LinksAdded) > 0 {
+		fmt.Fprintln(w, "added links:")
+		for _, link := range d.LinksAdded {
+			fmt.Fprintf(w, "  %s %s %s\n", link.From, link.Relation, link.To)
+		}
+	}
+	if len(d.LinksRemoved) > 0 {
+		fmt.Fprintln(w, "removed links:")
+		for _, link := range d.LinksRemoved {
+			fmt.Fprintf(w, "  %s %s %s\n", link.From, link.Relation, link.To)
+		}
+	}
+}
+
+func writeChanges(w io.Writer, title string, changes []fieldChange) {
+	if len(changes) == 0 {
+		return
+	}
+	fmt.Fprintln(w, title)
+	for _, change := range changes {
+		fmt.Fprintf(w, "  %s: %q -> %q\n", change.Key, change.From, change.To)
+	}
+}
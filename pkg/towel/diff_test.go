@@ -0,0 +1,112 @@
+package towel
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/marco-m/rosina"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	oldIssues, err := loadSnapshot("testdata/snapshot-old.json")
+	rosina.AssertNoError(t, err)
+	rosina.AssertEqual(t, len(oldIssues), 3, "old issues")
+	newIssues, err := loadSnapshot("testdata/snapshot-new.json")
+	rosina.AssertNoError(t, err)
+	rosina.AssertEqual(t, len(newIssues), 3, "new issues")
+
+	var bld strings.Builder
+	diffIssues(oldIssues, newIssues).writeText(&bld)
+
+	want := `added issues:
+  MANGO-10 Serve the mango
+removed issues:
+  MANGO-3 Throw away the stone
+status changes:
+  MANGO-1: "To Do" -> "In Progress"
+summary changes:
+  MANGO-2: "Slice the mango" -> "Slice the mango thinly"
+assignee changes:
+  MANGO-1: "Alice" -> "Bob"
+added links:
+  MANGO-2 blocks MANGO-10
+removed links:
+  MANGO-1 blocks MANGO-2
+`
+	rosina.AssertEqual(t, bld.String(), want, "diff")
+}
+
+func TestDiffSnapshotsNoChanges(t *testing.T) {
+	issues, err := loadSnapshot("testdata/snapshot-old.json")
+	rosina.AssertNoError(t, err)
+
+	var bld strings.Builder
+	diffIssues(issues, issues).writeText(&bld)
+
+	rosina.AssertEqual(t, bld.String(), "no changes\n", "diff")
+}
+
+func TestCompareKeys(t *testing.T) {
+	type testCase struct {
+		name string
+		a    string
+		b    string
+		want int
+	}
+
+	testCases := []testCase{
+		{name: "numeric order", a: "MANGO-9", b: "MANGO-10", want: -1},
+		{name: "same key", a: "MANGO-9", b: "MANGO-9", want: 0},
+		{name: "project first", a: "BANANA-10", b: "MANGO-9", want: -1},
+		{name: "malformed as strings", a: "MANGO", b: "MANGO-1", want: -1},
+	}
+
+	test := func(t *testing.T, tc testCase) {
+		rosina.AssertEqual(t, compareKeys(tc.a, tc.b), tc.want, "compareKeys")
+		rosina.AssertEqual(t, compareKeys(tc.b, tc.a), -tc.want, "compareKeys reversed")
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) { test(t, tc) })
+	}
+}
+
+func TestDiffArgs(t *testing.T) {
+	type testCase struct {
+		name          string
+		args          []string
+		wantRest      string
+		wantSnapshots string
+	}
+
+	testCases := []testCase{
+		{
+			name:          "snapshots after the command",
+			args:          []string{"diff", "old.json", "new.json"},
+			wantRest:      "diff",
+			wantSnapshots: "old.json new.json",
+		},
+		{
+			name:          "flags around the snapshots",
+			args:          []string{"--timeout", "1m", "diff", "old.json", "--json", "new.json"},
+			wantRest:      "--timeout 1m diff --json",
+			wantSnapshots: "old.json new.json",
+		},
+		{
+			name:          "other command",
+			args:          []string{"query", "--jql", "project = MANGO"},
+			wantRest:      "query --jql project = MANGO",
+			wantSnapshots: "",
+		},
+	}
+
+	test := func(t *testing.T, tc testCase) {
+		rest, snapshots := diffArgs(tc.args)
+		rosina.AssertEqual(t, strings.Join(rest, " "), tc.wantRest, "rest")
+		rosina.AssertEqual(t, strings.Join(snapshots, " "), tc.wantSnapshots, "snapshots")
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) { test(t, tc) })
+	}
+}
@@ -0,0 +1,23 @@
+// Package towel contains both the jira-towel command-line tool and a small Go
+// client of the Jira REST API, for programs that want to reuse the Jira
+// handling of the tool instead of parsing its output.
+//
+// Create a Client from a Config, either built by hand or loaded from the
+// configuration file created by 'jira-towel init':
+//
+//	dir, err := towel.DefaultConfigDir()
+//	...
+//	config, err := towel.LoadConfig(dir)
+//	...
+//	client := towel.NewClient(config)
+//	for issue, err := range client.Search(ctx, `project = MANGO`) {
+//	    ...
+//	}
+//
+// # Stability
+//
+// The project is at a very early stage and the API is NOT stable: exported
+// identifiers can change or disappear in any release, until the project
+// reaches version 1.0. If you depend on this package, pin the version and
+// read the release notes before upgrading.
+package towel
@@ -0,0 +1,196 @@
+package towel
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// dotRenderer renders a Graph in the graphviz DOT language.
+type dotRenderer struct {
+	rankdir   string
+	lut       map[string]int // See CustomfieldValue.
+	clusterBy []string       // Fields to cluster by, one per level; see clusterValues.
+	// Draw a ticket once per value of a multi-valued clusterBy field, instead
+	// of only for the first value; see clusterPaths.
+	clusterDuplicate bool
+	// Cluster by epic and parent instead of clusterBy; see newHierarchy.
+	hierarchy bool
+	// Edges to highlight because part of a dependency cycle; see cycleEdges.
+	cycleEdges map[*Edge]bool
+	// Edges to highlight because part of the critical path; see criticalEdges.
+	criticalEdges map[*Edge]bool
+	// Dependency state of the nodes, to mark blocked, blocking and ready
+	// issues and to write a legend; see dependencyStates. If nil, not marked.
+	states map[string]depState
+	// Write a legend of the styles used; see legendEntries. With states, the
+	// legend of the dependency states is written in any case.
+	legend bool
+	// If nil, the default theme.
+	theme *Theme
+	// If nil, defaultNodeLabel.
+	nodeLabel *nodeLabel
+	// Returns the URL of the web page of an issue, to make the nodes
+	// clickable in SVG output. If nil, nodes are not clickable.
+	browseURL func(key string) string
+	// The styles used while rendering.
+	used *legendEntries
+}
+
+func (r dotRenderer) Render(w io.Writer, g *Graph) error {
+	var bld strings.Builder
+	fmt.Fprintln(&bld, "digraph {")
+	fmt.Fprintf(&bld, "    rankdir=%s\n", r.rankdir)
+	if r.theme == nil {
+		r.theme = defaultTheme()
+	}
+	r.used = &legendEntries{}
+	if r.nodeLabel == nil {
+		label, err := parseNodeLabel(defaultNodeLabel, false, r.lut)
+		if err != nil {
+			return err
+		}
+		r.nodeLabel = label
+	}
+	fmt.Fprintf(&bld, "    node [%s]\n", r.theme.Node.attrs())
+	fmt.Fprintf(&bld, "    edge [%s]\n", r.theme.Edge.attrs())
+	fmt.Fprintln(&bld)
+
+	indent := "    "
+	clusters := &clusterTree{}
+	// The DOT node IDs of each issue: more than one if the issue is drawn in
+	// more than one cluster.
+	ids := make(map[string][]string)
+
+	// Sort everything, so that the same graph always gives the same output,
+	// no matter the order of the search result.
+	for _, node := range sortedNodes(g) {
+		// Placeholders are not part of the search result: do not cluster them.
+		if node.Placeholder || r.hierarchy || len(r.clusterBy) == 0 {
+			ids[node.Key] = []string{node.Key}
+			line, err := r.makeNode(node.Key, node, indent)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(&bld, line)
+			continue
+		}
+		paths := clusterPaths(node.Issue, r.clusterBy, r.lut, r.clusterDuplicate)
+		for i, path := range paths {
+			id := node.Key
+			if len(paths) > 1 {
+				id = fmt.Sprintf("%s (%d)", node.Key, i+1)
+			}
+			ids[node.Key] = append(ids[node.Key], id)
+			line, err := r.makeNode(id, node, indent)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(&bld, line)
+			clusters.add(path, id)
+		}
+	}
+	fmt.Fprintln(&bld)
+	for _, edge := range sortedEdges(g) {
+		for _, from := range ids[edge.From] {
+			for _, to := range ids[edge.To] {
+				fmt.Fprintln(&bld, r.makeEdge(edge, from, to, indent))
+			}
+		}
+	}
+	fmt.Fprintln(&bld)
+
+	if r.hierarchy {
+		newHierarchy(g, r.lut).writeClusters(&bld, g, indent)
+	} else {
+		clusters.write(&bld, indent)
+	}
+	r.used.write(&bld, indent)
+
+	fmt.Fprintln(&bld, "}")
+	_, err := io.WriteString(w, bld.String())
+	return err
+}
+
+// sortedNodes returns the nodes of 'g' sorted by key.
+func sortedNodes(g *Graph) []*Node {
+	return slices.SortedFunc(slices.Values(g.Nodes()), func(a, b *Node) int {
+		return compareKeys(a.Key, b.Key)
+	})
+}
+
+// sortedEdges returns the edges of 'g' sorted by source, destination and
+// link type.
+func sortedEdges(g *Graph) []*Edge {
+	return slices.SortedFunc(slices.Values(g.Edges()), func(a, b *Edge) int {
+		return cmp.Or(
+			compareKeys(a.From, b.From),
+			compareKeys(a.To, b.To),
+			cmp.Compare(a.Type.Name, b.Type.Name))
+	})
+}
+
+// makeNode returns the DOT node 'id' for 'node'.
+func (r dotRenderer) makeNode(id string, node *Node, indent string) (string, error) {
+	label, err := r.nodeLabel.executeNode(node)
+	if err != nil {
+		return "", err
+	}
+	if r.nodeLabel.html != nil {
+		label = "label=<" + label + ">"
+	} else {
+		label = dotAttrs("label", label)
+	}
+	style := r.theme.nodeStyle(node).merge(r.theme.stateStyle(r.states[node.Key]))
+	if r.legend {
+		r.used.addNode(r.theme.nodeRules(node)...)
+	}
+	r.used.addNode(r.theme.stateRules(r.states[node.Key])...)
+	attrs := strings.TrimSpace(label + " " + style.attrs())
+	if r.browseURL != nil {
+		attrs += " " + dotAttrs(
+			"URL", r.browseURL(node.Key),
+			"tooltip", nodeTooltip(node))
+	}
+	return fmt.Sprintf("%s%q [%s]", indent, id, attrs), nil
+}
+
+// nodeTooltip returns the tooltip of 'node': the full summary, the assignee and
+// the status, one per line.
+func nodeTooltip(node *Node) string {
+	fields := node.Issue.Fields
+	assignee := cmp.Or(assigneeName(node.Issue), "unassigned")
+	return fmt.Sprintf("%s %s\nassignee: %s\nstatus: %s",
+		node.Key, fields.Summary, assignee, cmp.Or(fields.Status.Name, "unknown"))
+}
+
+// makeEdge returns the DOT edge for 'edge', between DOT nodes 'from' and 'to'.
+func (r dotRenderer) makeEdge(edge *Edge, from, to string, indent string) string {
+	label := edge.Type.Outward
+	if len(edge.Links) > 0 {
+		label = fmt.Sprintf("%s (%d)", label, len(edge.Links))
+	}
+	rules := r.theme.edgeRules(edge)
+	if len(edge.Via) > 0 {
+		label = fmt.Sprintf("%s (via %s)", label, strings.Join(edge.Via, ", "))
+		rules = append(rules, edgeRule{"through hidden tickets", r.theme.Contracted})
+	}
+	if r.cycleEdges[edge] {
+		label += " (cycle)"
+		rules = append(rules, edgeRule{"dependency cycle", r.theme.Cycle})
+	}
+	if r.criticalEdges[edge] {
+		rules = append(rules, edgeRule{"critical path", r.theme.Critical})
+	}
+	var style EdgeStyle
+	for _, rule := range rules {
+		style = style.merge(rule.style)
+	}
+	if r.legend {
+		r.used.addEdge(rules...)
+	}
+	attrs := strings.TrimSpace(dotAttrs("label", label) + " " + style.attrs())
+	return fmt.Sprintf("%s%q -> %q [%s]", indent, from, to, attrs)
+}
@@ -0,0 +1,126 @@
+package towel
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// focusOptions controls focusGraph.
+type focusOptions struct {
+	// Keys of the issues to focus on.
+	keys []string
+	// Maximum distance of the ancestors (the issues blocking the focus).
+	upstream int
+	// Maximum distance of the descendants (the issues blocked by the focus).
+	downstream int
+	// Link types that form a dependency. See matchLinkType.
+	linkTypes []string
+	// If not nil, fetch the issues of the neighbourhood that are not in the
+	// graph (the placeholders) and add them marked as External.
+	client *Client
+}
+
+// focusGraph returns the neighbourhood of opts.keys: the subgraph of 'g' with
+// the focus issues, their ancestors up to opts.upstream links away and their
+// descendants up to opts.downstream links away, following only the dependency
+// edges. If opts.client is not nil, it also adds the missing issues to 'g'.
+func focusGraph(ctx context.Context, g *Graph, opts focusOptions) (*Graph, error) {
+	if opts.client != nil {
+		var missing []string
+		for _, key := range opts.keys {
+			if node, found := g.Node(key); !found || node.Placeholder {
+				missing = append(missing, key)
+			}
+		}
+		if len(missing) > 0 {
+			if _, err := fetchExternal(ctx, opts.client, g, missing); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, key := range opts.keys {
+		if _, found := g.Node(key); !found {
+			return nil, fmt.Errorf("focus: issue %s not found", key)
+		}
+	}
+
+	keep := make(map[string]bool)
+	for _, key := range opts.keys {
+		keep[key] = true
+	}
+
+	walk := func(distance int, upstream bool) error {
+		visited := make(map[string]bool)
+		frontier := opts.keys
+		for _, key := range frontier {
+			visited[key] = true
+		}
+		for range distance {
+			next := dependencyNeighbours(g, frontier, opts.linkTypes, upstream)
+			if opts.client != nil {
+				var missing []string
+				for _, key := range next {
+					if node, _ := g.Node(key); node.Placeholder {
+						missing = append(missing, key)
+					}
+				}
+				if len(missing) > 0 {
+					if _, err := fetchExternal(ctx, opts.client, g, missing); err != nil {
+						return err
+					}
+				}
+			}
+			frontier = nil
+			for _, key := range next {
+				if !visited[key] {
+					visited[key] = true
+					keep[key] = true
+					frontier = append(frontier, key)
+				}
+			}
+			if len(frontier) == 0 {
+				break
+			}
+		}
+		return nil
+	}
+	if err := walk(opts.upstream, true); err != nil {
+		return nil, err
+	}
+	if err := walk(opts.downstream, false); err != nil {
+		return nil, err
+	}
+
+	return g.subgraph(keep), nil
+}
+
+// dependencyNeighbours returns the sorted keys of the issues blocking 'keys'
+// if 'upstream', otherwise of the issues blocked by 'keys', considering only
+// the edges of 'linkTypes'.
+func dependencyNeighbours(g *Graph, keys []string, linkTypes []string, upstream bool,
+) []string {
+	seen := make(map[string]bool)
+	var neighbours []string
+	for _, key := range keys {
+		edges := g.OutEdges(key)
+		if upstream {
+			edges = g.InEdges(key)
+		}
+		for _, edge := range edges {
+			if !matchLinkType(edge.Type, linkTypes) {
+				continue
+			}
+			other := edge.To
+			if upstream {
+				other = edge.From
+			}
+			if !seen[other] {
+				seen[other] = true
+				neighbours = append(neighbours, other)
+			}
+		}
+	}
+	slices.SortFunc(neighbours, compareKeys)
+	return neighbours
+}
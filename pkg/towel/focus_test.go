@@ -0,0 +1,115 @@
+package towel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/marco-m/rosina"
+)
+
+func TestFocusGraph(t *testing.T) {
+	type testCase struct {
+		name      string
+		opts      focusOptions
+		wantNodes string
+		wantEdges string
+	}
+
+	relates := LinkType{Name: "Relates", Inward: "relates to", Outward: "relates to"}
+	// A-1 -> A-2 -> A-3 -> A-4 -> A-5, plus A-3 -> B-1, plus A-3 relates to R-1.
+	a3 := newIssue("A-3", "To Do", "A-4", "B-1")
+	a3.Fields.Issuelinks = append(a3.Fields.Issuelinks,
+		IssueLink{Type: relates, OutwardIssue: Issue{Key: "R-1"}})
+	issues := []Issue{
+		newIssue("A-1", "To Do", "A-2"),
+		newIssue("A-2", "To Do", "A-3"),
+		a3,
+		newIssue("A-4", "To Do", "A-5"),
+		newIssue("A-5", "To Do"),
+	}
+
+	testCases := []testCase{
+		{
+			name: "one link away",
+			opts: focusOptions{keys: []string{"A-3"}, upstream: 1, downstream: 1,
+				linkTypes: dependencyLinkTypes},
+			wantNodes: "A-2 A-3 A-4 B-1",
+			wantEdges: "A-2->A-3 A-3->A-4 A-3->B-1",
+		},
+		{
+			name: "only upstream",
+			opts: focusOptions{keys: []string{"A-3"}, upstream: 5,
+				linkTypes: dependencyLinkTypes},
+			wantNodes: "A-1 A-2 A-3",
+			wantEdges: "A-1->A-2 A-2->A-3",
+		},
+		{
+			name: "only downstream",
+			opts: focusOptions{keys: []string{"A-3"}, downstream: 2,
+				linkTypes: dependencyLinkTypes},
+			wantNodes: "A-3 A-4 B-1 A-5",
+			wantEdges: "A-3->A-4 A-3->B-1 A-4->A-5",
+		},
+		{
+			name:      "all link types",
+			opts:      focusOptions{keys: []string{"A-3"}, downstream: 1},
+			wantNodes: "A-3 A-4 B-1 R-1",
+			wantEdges: "A-3->A-4 A-3->B-1 A-3->R-1",
+		},
+		{
+			name: "more keys",
+			opts: focusOptions{keys: []string{"A-1", "A-5"}, upstream: 1,
+				downstream: 1, linkTypes: dependencyLinkTypes},
+			wantNodes: "A-1 A-2 A-4 A-5",
+			wantEdges: "A-1->A-2 A-4->A-5",
+		},
+	}
+
+	test := func(t *testing.T, tc testCase) {
+		g := NewGraph()
+		for _, ticket := range issues {
+			g.AddIssue(ticket)
+		}
+
+		sub, err := focusGraph(context.Background(), g, tc.opts)
+		rosina.AssertNoError(t, err)
+
+		rosina.AssertEqual(t, nodeKeys(sub.Nodes()), tc.wantNodes, "nodes")
+		rosina.AssertEqual(t, edgeNames(sub.Edges()), tc.wantEdges, "edges")
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) { test(t, tc) })
+	}
+}
+
+func TestFocusGraphFetchMissing(t *testing.T) {
+	// Like Jira, report the link on both of its issues.
+	x2 := newIssue("X-2", "To Do", "X-3")
+	x2.Fields.Issuelinks = append(x2.Fields.Issuelinks, isBlockedBy("X-1"))
+	client, _ := newIssueServer(t, newIssue("X-1", "To Do", "X-2"), x2,
+		newIssue("X-3", "To Do"))
+	g := NewGraph()
+	g.AddIssue(newIssue("A-1", "To Do"))
+
+	sub, err := focusGraph(context.Background(), g, focusOptions{
+		keys: []string{"X-2"}, upstream: 1, downstream: 1,
+		linkTypes: dependencyLinkTypes, client: client,
+	})
+	rosina.AssertNoError(t, err)
+
+	rosina.AssertEqual(t, nodeKeys(sub.Nodes()), "X-2 X-3 X-1", "nodes")
+	for _, node := range sub.Nodes() {
+		rosina.AssertEqual(t, node.Placeholder, false, node.Key+" placeholder")
+		rosina.AssertEqual(t, node.External, true, node.Key+" external")
+	}
+}
+
+func TestFocusGraphNotFound(t *testing.T) {
+	g := NewGraph()
+	g.AddIssue(newIssue("A-1", "To Do"))
+
+	_, err := focusGraph(context.Background(), g, focusOptions{keys: []string{"B-1"}})
+
+	rosina.AssertEqual(t, err.Error(), "focus: issue B-1 not found", "error")
+}
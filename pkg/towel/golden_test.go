@@ -0,0 +1,124 @@
+package towel
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata/golden")
+
+// TestDotRendererGolden renders the fixture issues and compares the output
+// with the golden files. After an intended change of the output, update the
+// golden files with:
+//
+//	go test ./pkg/towel -run TestDotRendererGolden -update
+func TestDotRendererGolden(t *testing.T) {
+	type testCase struct {
+		name     string
+		renderer dotRenderer
+		rollup   bool
+		states   bool
+	}
+
+	lut := map[string]int{"product": 11919}
+	testCases := []testCase{
+		{
+			name:     "default",
+			renderer: dotRenderer{rankdir: "LR"},
+		},
+		{
+			name: "cluster-by",
+			renderer: dotRenderer{rankdir: "LR", lut: lut,
+				clusterBy: []string{"product", "label"}, clusterDuplicate: true},
+		},
+		{
+			name:     "hierarchy",
+			renderer: dotRenderer{rankdir: "TB", lut: lut, hierarchy: true},
+		},
+		{
+			name:     "colorblind",
+			renderer: dotRenderer{rankdir: "LR", theme: colorblindTheme()},
+		},
+		{
+			name: "links",
+			renderer: dotRenderer{rankdir: "LR", browseURL: func(key string) string {
+				return "https://example.atlassian.net/browse/" + key
+			}},
+		},
+		{
+			name:     "states",
+			renderer: dotRenderer{rankdir: "LR"},
+			states:   true,
+		},
+		{
+			name:     "legend",
+			renderer: dotRenderer{rankdir: "LR", legend: true},
+			states:   true,
+		},
+		{
+			name:     "legend-colorblind",
+			renderer: dotRenderer{rankdir: "LR", legend: true, theme: colorblindTheme()},
+		},
+		{
+			name:     "rollup",
+			renderer: dotRenderer{rankdir: "LR"},
+			rollup:   true,
+		},
+	}
+
+	issues, err := loadSnapshot("testdata/graph-issues.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	render := func(t *testing.T, tc testCase, issues []Issue) []byte {
+		g := NewGraph()
+		for _, ticket := range issues {
+			g.AddIssue(ticket)
+		}
+		if tc.rollup {
+			g = rollupByEpic(g, lut)
+		}
+		if tc.states {
+			tc.renderer.states = dependencyStates(g, dependencyLinkTypes)
+		}
+		var buf bytes.Buffer
+		if err := tc.renderer.Render(&buf, g); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+
+	test := func(t *testing.T, tc testCase) {
+		have := render(t, tc, issues)
+
+		golden := filepath.Join("testdata", "golden", tc.name+".dot")
+		if *update {
+			if err := os.WriteFile(golden, have, 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		want, err := os.ReadFile(golden)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(have, want) {
+			t.Fatalf("output differs from %s (rerun with -update if intended)\nhave:\n%s",
+				golden, have)
+		}
+
+		reversed := slices.Clone(issues)
+		slices.Reverse(reversed)
+		if !bytes.Equal(render(t, tc, reversed), have) {
+			t.Fatal("output depends on the order of the issues")
+		}
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) { test(t, tc) })
+	}
+}
@@ -0,0 +1,261 @@
+package towel
+
+import (
+	"context"
+	"io"
+	"slices"
+)
+
+// Graph is a directed graph of issues, where the edges are the issue links.
+// Nodes and edges are deduplicated: adding the same issue or the same link
+// twice has no effect. Nodes and edges are kept in insertion order.
+//
+// A link can point to an issue that has not been added (for example because
+// it is not part of the search result). Such an issue is represented by a
+// placeholder node, containing the partial information that Jira embeds in
+// the link (key, summary, status, issue type). If the issue is added later,
+// the placeholder becomes a normal node.
+type Graph struct {
+	nodes     map[string]*Node
+	nodeOrder []*Node
+	edges     map[edgeKey]*Edge
+	edgeOrder []*Edge
+	outEdges  map[string][]*Edge
+	inEdges   map[string][]*Edge
+	// If not nil, AddIssue adds only the links it accepts; see setLinkFilter.
+	linkFilter func(LinkType) bool
+}
+
+// Node is an issue in a Graph.
+type Node struct {
+	Key   string
+	Issue Issue
+	// Placeholder is true if the issue has not been added to the graph, but is
+	// only known because another issue links to it. In this case, Issue
+	// contains only the partial information embedded by Jira in the link.
+	Placeholder bool
+	// External is true if the issue is not part of the search result, but
+	// has been fetched by following the links; see followLinks.
+	External bool
+}
+
+// Edge is a link between two issues in a Graph, always in the outward
+// direction of the link type. For example, for link type "Blocks", an Edge
+// goes From the issue that "blocks" To the issue that "is blocked by".
+type Edge struct {
+	From string
+	To   string
+	Type LinkType
+	// Links are the underlying edges of an edge created by a rollup; see
+	// rollupByEpic.
+	Links []*Edge
+	// Via are the keys of the hidden issues that an edge created by a
+	// contraction goes through, in order; see hideIssues.
+	Via []string
+}
+
+type edgeKey struct {
+	from     string
+	to       string
+	linkType string
+}
+
+// Renderer renders a Graph, for example in the graphviz DOT language.
+type Renderer interface {
+	Render(w io.Writer, g *Graph) error
+}
+
+// NewGraph returns an empty Graph.
+func NewGraph() *Graph {
+	return &Graph{
+		nodes:    make(map[string]*Node),
+		edges:    make(map[edgeKey]*Edge),
+		outEdges: make(map[string][]*Edge),
+		inEdges:  make(map[string][]*Edge),
+	}
+}
+
+// AddIssue adds 'ticket' to the graph, together with its links. If the issue
+// is already present, its information is replaced.
+//
+// Jira reports a link on both of its issues: as an outward link on one and as
+// an inward link on the other. Both are normalized to the same Edge, in the
+// outward direction, so that the graph is complete no matter which side of
+// the link has been added, and has no duplicate edges when both have.
+func (g *Graph) AddIssue(ticket Issue) {
+	node := g.addNode(ticket.Key)
+	node.Issue = ticket
+	node.Placeholder = false
+
+	for _, link := range ticket.Fields.Issuelinks {
+		if g.linkFilter != nil && !g.linkFilter(link.Type) {
+			continue
+		}
+		// NOTE It should be impossible to have both Outward and Inward at
+		// the same time...
+		if link.OutwardIssue.Key != "" {
+			g.addPlaceholder(link.OutwardIssue)
+			g.addEdge(ticket.Key, link.OutwardIssue.Key, link.Type)
+		}
+		if link.InwardIssue.Key != "" {
+			g.addPlaceholder(link.InwardIssue)
+			g.addEdge(link.InwardIssue.Key, ticket.Key, link.Type)
+		}
+	}
+}
+
+// addNode returns the node with 'key', creating it as a placeholder if needed.
+func (g *Graph) addNode(key string) *Node {
+	if node, found := g.nodes[key]; found {
+		return node
+	}
+	node := &Node{Key: key, Issue: Issue{Key: key}, Placeholder: true}
+	g.nodes[key] = node
+	g.nodeOrder = append(g.nodeOrder, node)
+	return node
+}
+
+// addPlaceholder adds the partial issue 'ticket', unless already present.
+func (g *Graph) addPlaceholder(ticket Issue) {
+	if _, found := g.nodes[ticket.Key]; found {
+		return
+	}
+	node := g.addNode(ticket.Key)
+	node.Issue = ticket
+}
+
+func (g *Graph) addEdge(from, to string, linkType LinkType) {
+	key := edgeKey{from: from, to: to, linkType: linkType.Name}
+	if _, found := g.edges[key]; found {
+		return
+	}
+	edge := &Edge{From: from, To: to, Type: linkType}
+	g.edges[key] = edge
+	g.edgeOrder = append(g.edgeOrder, edge)
+	g.outEdges[from] = append(g.outEdges[from], edge)
+	g.inEdges[to] = append(g.inEdges[to], edge)
+}
+
+// removeEdge removes 'edge' from the graph, if present.
+func (g *Graph) removeEdge(edge *Edge) {
+	key := edgeKey{from: edge.From, to: edge.To, linkType: edge.Type.Name}
+	if g.edges[key] != edge {
+		return
+	}
+	delete(g.edges, key)
+	remove := func(edges []*Edge) []*Edge {
+		return slices.DeleteFunc(edges, func(e *Edge) bool { return e == edge })
+	}
+	g.edgeOrder = remove(g.edgeOrder)
+	g.outEdges[edge.From] = remove(g.outEdges[edge.From])
+	g.inEdges[edge.To] = remove(g.inEdges[edge.To])
+}
+
+// removeNode removes the node with 'key' from 'g', together with its edges.
+func (g *Graph) removeNode(key string) {
+	node, found := g.nodes[key]
+	if !found {
+		return
+	}
+	for _, edge := range slices.Concat(g.outEdges[key], g.inEdges[key]) {
+		g.removeEdge(edge)
+	}
+	delete(g.nodes, key)
+	delete(g.outEdges, key)
+	delete(g.inEdges, key)
+	g.nodeOrder = slices.DeleteFunc(g.nodeOrder, func(n *Node) bool { return n == node })
+}
+
+// setLinkFilter removes the edges of 'g' not accepted by 'accept', together
+// with the placeholders left without edges (they were in the graph only because
+// of a link), and makes AddIssue skip such links from now on.
+func (g *Graph) setLinkFilter(accept func(LinkType) bool) {
+	g.linkFilter = accept
+	// Iterate over a copy: removeEdge modifies g.edgeOrder.
+	for _, edge := range slices.Clone(g.Edges()) {
+		if !accept(edge.Type) {
+			g.removeEdge(edge)
+		}
+	}
+	g.nodeOrder = slices.DeleteFunc(g.nodeOrder, func(node *Node) bool {
+		orphan := node.Placeholder &&
+			len(g.outEdges[node.Key]) == 0 && len(g.inEdges[node.Key]) == 0
+		if orphan {
+			delete(g.nodes, node.Key)
+		}
+		return orphan
+	})
+}
+
+// subgraph returns a new Graph with the nodes of 'g' in 'keep' and the edges
+// between them.
+func (g *Graph) subgraph(keep map[string]bool) *Graph {
+	sub := NewGraph()
+	sub.linkFilter = g.linkFilter
+	for _, node := range g.nodeOrder {
+		if keep[node.Key] {
+			clone := *node
+			sub.nodes[node.Key] = &clone
+			sub.nodeOrder = append(sub.nodeOrder, &clone)
+		}
+	}
+	for _, edge := range g.edgeOrder {
+		if keep[edge.From] && keep[edge.To] {
+			sub.addEdge(edge.From, edge.To, edge.Type)
+			key := edgeKey{from: edge.From, to: edge.To, linkType: edge.Type.Name}
+			sub.edges[key].Links = edge.Links
+			sub.edges[key].Via = edge.Via
+		}
+	}
+	return sub
+}
+
+// searchGraph returns a Graph containing the issues matching 'jql'.
+func searchGraph(ctx context.Context, client *Client, jql string) (*Graph, error) {
+	g := NewGraph()
+	for ticket, err := range client.Search(ctx, jql) {
+		if err != nil {
+			return nil, err
+		}
+		g.AddIssue(ticket)
+	}
+	return g, nil
+}
+
+// Node returns the node with 'key', if present.
+func (g *Graph) Node(key string) (*Node, bool) {
+	node, found := g.nodes[key]
+	return node, found
+}
+
+// Nodes returns all the nodes, including the placeholders, in insertion order.
+func (g *Graph) Nodes() []*Node {
+	return g.nodeOrder
+}
+
+// Issues returns the issues of all the nodes that are not placeholders, in
+// insertion order.
+func (g *Graph) Issues() []Issue {
+	var issues []Issue
+	for _, node := range g.nodeOrder {
+		if !node.Placeholder {
+			issues = append(issues, node.Issue)
+		}
+	}
+	return issues
+}
+
+// Edges returns all the edges, in insertion order.
+func (g *Graph) Edges() []*Edge {
+	return g.edgeOrder
+}
+
+// OutEdges returns the edges starting from the node with 'key'.
+func (g *Graph) OutEdges(key string) []*Edge {
+	return g.outEdges[key]
+}
+
+// InEdges returns the edges arriving to the node with 'key'.
+func (g *Graph) InEdges(key string) []*Edge {
+	return g.inEdges[key]
+}
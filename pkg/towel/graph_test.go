@@ -0,0 +1,174 @@
+package towel
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/marco-m/rosina"
+)
+
+var blocks = LinkType{Name: "Blocks", Inward: "is blocked by", Outward: "blocks"}
+
+// newIssue returns an issue with 'key', status 'status' and outward links
+// of type 'Blocks' to 'blocked'.
+func newIssue(key string, status string, blocked ...string) Issue {
+	ticket := Issue{
+		Key: key,
+		Fields: Fields{
+			Summary: "summary of " + key,
+			Status:  Status{Name: status},
+		},
+	}
+	for _, dst := range blocked {
+		ticket.Fields.Issuelinks = append(ticket.Fields.Issuelinks, IssueLink{
+			Type:         blocks,
+			OutwardIssue: Issue{Key: dst},
+		})
+	}
+	return ticket
+}
+
+func nodeKeys(nodes []*Node) string {
+	keys := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		keys = append(keys, node.Key)
+	}
+	return strings.Join(keys, " ")
+}
+
+func edgeNames(edges []*Edge) string {
+	names := make([]string, 0, len(edges))
+	for _, edge := range edges {
+		names = append(names, edge.From+"->"+edge.To)
+	}
+	return strings.Join(names, " ")
+}
+
+func TestGraphDeduplicatesNodesAndEdges(t *testing.T) {
+	g := NewGraph()
+	g.AddIssue(newIssue("A-1", "To Do", "A-2"))
+	g.AddIssue(newIssue("A-1", "In Progress", "A-2"))
+	g.AddIssue(newIssue("A-2", "To Do"))
+
+	rosina.AssertEqual(t, nodeKeys(g.Nodes()), "A-1 A-2", "nodes")
+	rosina.AssertEqual(t, edgeNames(g.Edges()), "A-1->A-2", "edges")
+	node, _ := g.Node("A-1")
+	rosina.AssertEqual(t, node.Issue.Fields.Status.Name, "In Progress",
+		"the last added issue wins")
+}
+
+func TestGraphPlaceholders(t *testing.T) {
+	g := NewGraph()
+	ticket := newIssue("A-1", "To Do", "B-7")
+	ticket.Fields.Issuelinks[0].OutwardIssue.Fields.Summary = "from the link"
+	g.AddIssue(ticket)
+
+	node, found := g.Node("B-7")
+	rosina.AssertEqual(t, found, true, "placeholder found")
+	rosina.AssertEqual(t, node.Placeholder, true, "placeholder")
+	rosina.AssertEqual(t, node.Issue.Fields.Summary, "from the link",
+		"partial information from the link")
+
+	g.AddIssue(newIssue("B-7", "Done"))
+
+	node, _ = g.Node("B-7")
+	rosina.AssertEqual(t, node.Placeholder, false, "placeholder replaced")
+	rosina.AssertEqual(t, node.Issue.Fields.Status.Name, "Done", "status")
+	rosina.AssertEqual(t, nodeKeys(g.Nodes()), "A-1 B-7", "nodes")
+}
+
+// isBlockedBy returns an inward link of type 'Blocks' from 'src'.
+func isBlockedBy(src string) IssueLink {
+	return IssueLink{Type: blocks, InwardIssue: Issue{Key: src}}
+}
+
+func TestGraphInwardLinks(t *testing.T) {
+	type testCase struct {
+		name   string
+		issues []Issue
+		want   string
+	}
+
+	blocked := newIssue("A-2", "To Do")
+	blocked.Fields.Issuelinks = []IssueLink{isBlockedBy("A-1")}
+
+	testCases := []testCase{
+		{
+			name:   "only the outward side",
+			issues: []Issue{newIssue("A-1", "To Do", "A-2")},
+			want:   "A-1->A-2",
+		},
+		{
+			name:   "only the inward side",
+			issues: []Issue{blocked},
+			want:   "A-1->A-2",
+		},
+		{
+			name:   "both sides, outward first",
+			issues: []Issue{newIssue("A-1", "To Do", "A-2"), blocked},
+			want:   "A-1->A-2",
+		},
+		{
+			name:   "both sides, inward first",
+			issues: []Issue{blocked, newIssue("A-1", "To Do", "A-2")},
+			want:   "A-1->A-2",
+		},
+	}
+
+	test := func(t *testing.T, tc testCase) {
+		g := NewGraph()
+		for _, ticket := range tc.issues {
+			g.AddIssue(ticket)
+		}
+
+		rosina.AssertEqual(t, edgeNames(g.Edges()), tc.want, "edges")
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) { test(t, tc) })
+	}
+}
+
+func TestGraphSameIssuesDifferentLinkTypes(t *testing.T) {
+	relates := LinkType{Name: "Relates", Inward: "relates to", Outward: "relates to"}
+	ticket := newIssue("A-1", "To Do", "A-2")
+	ticket.Fields.Issuelinks = append(ticket.Fields.Issuelinks,
+		IssueLink{Type: relates, OutwardIssue: Issue{Key: "A-2"}})
+
+	g := NewGraph()
+	g.AddIssue(ticket)
+
+	rosina.AssertEqual(t, len(g.Edges()), 2, "one edge per link type")
+}
+
+func TestGraphAdjacency(t *testing.T) {
+	g := NewGraph()
+	g.AddIssue(newIssue("A-1", "To Do", "A-2", "A-3"))
+	g.AddIssue(newIssue("A-2", "To Do", "A-3"))
+
+	rosina.AssertEqual(t, edgeNames(g.OutEdges("A-1")), "A-1->A-2 A-1->A-3",
+		"out edges of A-1")
+	rosina.AssertEqual(t, edgeNames(g.InEdges("A-3")), "A-1->A-3 A-2->A-3",
+		"in edges of A-3")
+	rosina.AssertEqual(t, edgeNames(g.InEdges("A-1")), "", "in edges of A-1")
+}
+
+func TestDotRendererMarksPlaceholders(t *testing.T) {
+	g := NewGraph()
+	g.AddIssue(newIssue("A-1", "To Do", "B-7"))
+
+	var bld strings.Builder
+	err := dotRenderer{rankdir: "LR"}.Render(&bld, g)
+	rosina.AssertNoError(t, err)
+
+	dot := bld.String()
+	rosina.AssertEqual(t,
+		strings.Contains(dot, `"A-1" [label="summary of A-1\nA-1 To Do" fillcolor="cadetblue1"]`),
+		true, "fetched node")
+	rosina.AssertEqual(t,
+		strings.Contains(dot, `"B-7" [label="\nB-7 " fillcolor="white" style="filled,dashed"]`),
+		true, "placeholder node")
+	rosina.AssertEqual(t,
+		strings.Contains(dot, `"A-1" -> "B-7" [label="blocks" color="red"]`),
+		true, "edge")
+}
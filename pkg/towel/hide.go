@@ -0,0 +1,60 @@
+package towel
+
+import (
+	"slices"
+	"strings"
+)
+
+// matchStatus returns true if the status of 'ticket' matches one of 'names'.
+// A name matches, case-insensitively, either the name of the status ("In
+// Review") or its status category, by key ("done") or by name ("Done").
+func matchStatus(ticket Issue, names []string) bool {
+	status := ticket.Fields.Status
+	for _, name := range names {
+		if strings.EqualFold(name, status.Name) ||
+			(status.StatusCategory.Key != "" &&
+				strings.EqualFold(name, status.StatusCategory.Key)) ||
+			(status.StatusCategory.Name != "" &&
+				strings.EqualFold(name, status.StatusCategory.Name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// hideIssues removes from 'g' the issues with a status matching one of
+// 'statuses' (see matchStatus), and returns their number.
+//
+// To preserve the chains going through a hidden issue, each pair of an
+// incoming and an outgoing edge of the same link type is contracted into a
+// single edge, marked with the hidden issues it goes through (see Edge.Via):
+// if A blocks B blocks C and B is hidden, then A blocks C. If the contracted
+// edge is already in the graph, the existing one is kept.
+func hideIssues(g *Graph, statuses []string) int {
+	var hidden []string
+	for _, node := range g.Nodes() {
+		if matchStatus(node.Issue, statuses) {
+			hidden = append(hidden, node.Key)
+		}
+	}
+	// Sort, so that the same graph is always contracted in the same way.
+	slices.SortFunc(hidden, compareKeys)
+
+	for _, key := range hidden {
+		for _, in := range g.InEdges(key) {
+			for _, out := range g.OutEdges(key) {
+				if in.Type.Name != out.Type.Name || in.From == out.To {
+					continue
+				}
+				edgeKey := edgeKey{from: in.From, to: out.To, linkType: in.Type.Name}
+				if _, found := g.edges[edgeKey]; found {
+					continue
+				}
+				g.addEdge(in.From, out.To, in.Type)
+				g.edges[edgeKey].Via = slices.Concat(in.Via, []string{key}, out.Via)
+			}
+		}
+		g.removeNode(key)
+	}
+	return len(hidden)
+}
@@ -0,0 +1,155 @@
+package towel
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/marco-m/rosina"
+)
+
+func TestHideIssues(t *testing.T) {
+	type testCase struct {
+		name       string
+		issues     []Issue
+		wantHidden int
+		wantNodes  string
+		wantEdges  string
+	}
+
+	relates := LinkType{Name: "Relates", Inward: "relates to", Outward: "relates to"}
+	// edgeVia returns the edges of 'g', with the issues they go through.
+	edgeVia := func(g *Graph) string {
+		var names []string
+		for _, edge := range g.Edges() {
+			name := edge.From + "->" + edge.To
+			if len(edge.Via) > 0 {
+				name += "(" + strings.Join(edge.Via, ",") + ")"
+			}
+			names = append(names, name)
+		}
+		return strings.Join(names, " ")
+	}
+
+	testCases := []testCase{
+		{
+			name: "nothing to hide",
+			issues: []Issue{
+				newIssue("A-1", "To Do", "A-2"),
+				newIssue("A-2", "To Do"),
+			},
+			wantNodes: "A-1 A-2",
+			wantEdges: "A-1->A-2",
+		},
+		{
+			name: "chain through a hidden issue",
+			issues: []Issue{
+				newIssue("A-1", "To Do", "A-2"),
+				newIssue("A-2", "Done", "A-3"),
+				newIssue("A-3", "To Do"),
+			},
+			wantHidden: 1,
+			wantNodes:  "A-1 A-3",
+			wantEdges:  "A-1->A-3(A-2)",
+		},
+		{
+			name: "chain through many hidden issues",
+			issues: []Issue{
+				newIssue("A-1", "To Do", "A-3"),
+				newIssue("A-3", "Done", "A-2"),
+				newIssue("A-2", "Done", "A-4"),
+				newIssue("A-4", "To Do"),
+			},
+			wantHidden: 2,
+			wantNodes:  "A-1 A-4",
+			wantEdges:  "A-1->A-4(A-3,A-2)",
+		},
+		{
+			name: "fan in and fan out",
+			issues: []Issue{
+				newIssue("A-1", "To Do", "A-3"),
+				newIssue("A-2", "To Do", "A-3"),
+				newIssue("A-3", "Done", "A-4", "A-5"),
+			},
+			wantHidden: 1,
+			wantNodes:  "A-1 A-2 A-4 A-5",
+			wantEdges:  "A-1->A-4(A-3) A-1->A-5(A-3) A-2->A-4(A-3) A-2->A-5(A-3)",
+		},
+		{
+			name: "an existing link is kept",
+			issues: []Issue{
+				newIssue("A-1", "To Do", "A-2", "A-3"),
+				newIssue("A-2", "Done", "A-3"),
+			},
+			wantHidden: 1,
+			wantNodes:  "A-1 A-3",
+			wantEdges:  "A-1->A-3",
+		},
+		{
+			name: "different link types are not contracted",
+			issues: []Issue{
+				newIssue("A-1", "To Do", "A-2"),
+				{
+					Key: "A-2",
+					Fields: Fields{
+						Status: Status{Name: "Done"},
+						Issuelinks: []IssueLink{
+							{Type: relates, OutwardIssue: Issue{Key: "A-3"}},
+						},
+					},
+				},
+				newIssue("A-3", "To Do"),
+			},
+			wantHidden: 1,
+			wantNodes:  "A-1 A-3",
+			wantEdges:  "",
+		},
+		{
+			name: "by status category",
+			issues: []Issue{
+				newIssue("A-1", "To Do", "A-2"),
+				{
+					Key: "A-2",
+					Fields: Fields{Status: Status{
+						Name:           "Closed",
+						StatusCategory: StatusCategory{Key: "done", Name: "Done"},
+					}},
+				},
+			},
+			wantHidden: 1,
+			wantNodes:  "A-1",
+			wantEdges:  "",
+		},
+	}
+
+	test := func(t *testing.T, tc testCase) {
+		g := NewGraph()
+		for _, ticket := range tc.issues {
+			g.AddIssue(ticket)
+		}
+
+		hidden := hideIssues(g, []string{"done"})
+
+		rosina.AssertEqual(t, hidden, tc.wantHidden, "hidden")
+		rosina.AssertEqual(t, nodeKeys(g.Nodes()), tc.wantNodes, "nodes")
+		rosina.AssertEqual(t, edgeVia(g), tc.wantEdges, "edges")
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) { test(t, tc) })
+	}
+}
+
+func TestDotRendererMarksContractedEdges(t *testing.T) {
+	g := NewGraph()
+	g.AddIssue(newIssue("A-1", "To Do", "A-2"))
+	g.AddIssue(newIssue("A-2", "Done", "A-3"))
+	g.AddIssue(newIssue("A-3", "To Do"))
+	hideIssues(g, []string{"done"})
+
+	var bld strings.Builder
+	err := dotRenderer{rankdir: "LR"}.Render(&bld, g)
+	rosina.AssertNoError(t, err)
+
+	want := `"A-1" -> "A-3" [label="blocks (via A-2)" color="red" arrowhead="empty" fontcolor="gray40"]`
+	rosina.AssertEqual(t, strings.Contains(bld.String(), want), true, want)
+}
@@ -0,0 +1,111 @@
+package towel
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/marco-m/jira-towel/pkg/text"
+)
+
+// epicLinkField is the name of the custom field (see --custom-fields) holding
+// the legacy "Epic Link", used when the issue has no parent.
+const epicLinkField = "epic-link"
+
+// parentOf returns the parent of 'ticket' (for a story, its epic; for a
+// subtask, its story), falling back to the legacy "Epic Link" custom field.
+// The parent contains only the information embedded by Jira.
+func parentOf(ticket Issue, lut map[string]int) (Issue, bool) {
+	if parent := ticket.Fields.Parent; parent != nil && parent.Key != "" {
+		return *parent, true
+	}
+	cf := lookupCustomField(ticket.Fields.CustomFields, lut, epicLinkField)
+	if epic := cf.Text(); epic != "" {
+		return Issue{Key: epic}, true
+	}
+	return Issue{}, false
+}
+
+// hierarchy is the parent/child tree of the nodes of a Graph. A parent not in
+// the graph is still part of the tree, with the partial information from its
+// children.
+type hierarchy struct {
+	parents  map[string]Issue    // Issues that have at least one child.
+	children map[string][]string // Children of each parent, sorted by key.
+	roots    []string            // Parents without a parent, sorted by key.
+}
+
+func newHierarchy(g *Graph, lut map[string]int) hierarchy {
+	h := hierarchy{
+		parents:  make(map[string]Issue),
+		children: make(map[string][]string),
+	}
+	hasParent := make(map[string]bool)
+	var order []string
+	for _, node := range g.Nodes() {
+		parent, found := parentOf(node.Issue, lut)
+		if !found {
+			continue
+		}
+		hasParent[node.Key] = true
+		if _, seen := h.parents[parent.Key]; !seen {
+			order = append(order, parent.Key)
+			h.parents[parent.Key] = parent
+		}
+		h.children[parent.Key] = append(h.children[parent.Key], node.Key)
+	}
+	// Prefer the complete information of the parents that are in the graph.
+	for key := range h.parents {
+		if node, found := g.Node(key); found {
+			h.parents[key] = node.Issue
+		}
+	}
+	for _, key := range order {
+		if !hasParent[key] {
+			h.roots = append(h.roots, key)
+		}
+	}
+	// Sort, so that the output does not depend on the order of the nodes.
+	slices.SortFunc(h.roots, compareKeys)
+	for _, children := range h.children {
+		slices.SortFunc(children, compareKeys)
+	}
+	return h
+}
+
+// clusterColors are the fill colors of the nested clusters, by depth.
+var clusterColors = []string{"aquamarine", "paleturquoise1", "azure"}
+
+// writeClusters writes the hierarchy as nested clusters: each parent is a
+// cluster containing itself (if in 'g') and its children.
+func (h hierarchy) writeClusters(bld *strings.Builder, g *Graph, indent string) {
+	visited := make(map[string]bool)
+	var write func(key string, depth int)
+	write = func(key string, depth int) {
+		prefix := strings.Repeat(indent, depth+1)
+		// Jira forbids loops in the hierarchy, but better safe than sorry.
+		if _, isParent := h.parents[key]; !isParent || visited[key] {
+			fmt.Fprintf(bld, "%s%q\n", prefix, key)
+			return
+		}
+		visited[key] = true
+
+		parent := h.parents[key]
+		label := fmt.Sprintf("%s %s", key,
+			text.ShortenMiddle(parent.Fields.Summary, 40))
+		color := clusterColors[min(depth, len(clusterColors)-1)]
+		fmt.Fprintf(bld, "%ssubgraph %q {\n", prefix, "cluster_"+key)
+		fmt.Fprintf(bld, "%s%slabel=%q style=filled color=%q\n", prefix, indent,
+			strings.TrimSpace(label), color)
+		if _, found := g.Node(key); found {
+			fmt.Fprintf(bld, "%s%s%q\n", prefix, indent, key)
+		}
+		for _, child := range h.children[key] {
+			write(child, depth+1)
+		}
+		fmt.Fprintf(bld, "%s}\n", prefix)
+	}
+	for _, key := range h.roots {
+		write(key, 0)
+	}
+}
@@ -0,0 +1,89 @@
+package towel
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/marco-m/rosina"
+)
+
+func TestParentOf(t *testing.T) {
+	type testCase struct {
+		name   string
+		ticket Issue
+		want   string
+	}
+
+	lut := map[string]int{"epic-link": 10014}
+	withParent := newIssue("A-2", "To Do")
+	withParent.Fields.Parent = &Issue{Key: "A-1"}
+	withEpicLink := newIssue("A-3", "To Do")
+	withEpicLink.Fields.CustomFields = map[string]CustomField{
+		"customfield_10014": CustomField(json.RawMessage(`"A-1"`)),
+	}
+	withBoth := withEpicLink
+	withBoth.Fields.Parent = &Issue{Key: "A-2"}
+
+	testCases := []testCase{
+		{name: "no parent", ticket: newIssue("A-1", "To Do"), want: ""},
+		{name: "parent", ticket: withParent, want: "A-1"},
+		{name: "legacy epic link", ticket: withEpicLink, want: "A-1"},
+		{name: "parent wins", ticket: withBoth, want: "A-2"},
+	}
+
+	test := func(t *testing.T, tc testCase) {
+		parent, found := parentOf(tc.ticket, lut)
+
+		rosina.AssertEqual(t, parent.Key, tc.want, "parent")
+		rosina.AssertEqual(t, found, tc.want != "", "found")
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) { test(t, tc) })
+	}
+}
+
+func TestDotRendererHierarchy(t *testing.T) {
+	child := func(key string, parent string, blocked ...string) Issue {
+		ticket := newIssue(key, "To Do", blocked...)
+		ticket.Fields.Parent = &Issue{Key: parent}
+		ticket.Fields.Parent.Fields.Summary = "summary of " + parent
+		return ticket
+	}
+	g := NewGraph()
+	g.AddIssue(newIssue("E-1", "To Do"))
+	g.AddIssue(child("S-1", "E-1", "S-3"))
+	g.AddIssue(child("T-1", "S-1"))
+	g.AddIssue(child("S-2", "E-1"))
+	// Parent E-2 is not in the graph.
+	g.AddIssue(child("S-3", "E-2"))
+	g.AddIssue(newIssue("X-1", "To Do"))
+
+	var bld strings.Builder
+	err := dotRenderer{rankdir: "LR", hierarchy: true}.Render(&bld, g)
+	rosina.AssertNoError(t, err)
+
+	dot := bld.String()
+	clusters := dot[strings.Index(dot, `    subgraph`):]
+	want := `    subgraph "cluster_E-1" {
+        label="E-1 summary of E-1" style=filled color="aquamarine"
+        "E-1"
+        subgraph "cluster_S-1" {
+            label="S-1 summary of S-1" style=filled color="paleturquoise1"
+            "S-1"
+            "T-1"
+        }
+        "S-2"
+    }
+    subgraph "cluster_E-2" {
+        label="E-2 summary of E-2" style=filled color="aquamarine"
+        "S-3"
+    }
+}
+`
+	rosina.AssertEqual(t, clusters, want, "clusters")
+	rosina.AssertEqual(t,
+		strings.Contains(dot, `"S-1" -> "S-3" [label="blocks" color="red"]`),
+		true, "edge across clusters")
+}
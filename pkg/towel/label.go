@@ -0,0 +1,148 @@
+package towel
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/marco-m/jira-towel/pkg/text"
+)
+
+// defaultNodeLabel is the template of the node labels, unless specified
+// otherwise with --node-label.
+const defaultNodeLabel = "{{shorten 40 .Summary}}\n{{.Key}} {{.Status.Name}}"
+
+// labelData is the data of the node label template: all the fields of the
+// issue, plus its key and the state of the node. The optional fields are not
+// pointers, so that the template does not need to check for nil: for
+// example, {{.Assignee.DisplayName}} is empty for an unassigned issue.
+type labelData struct {
+	Fields
+	Key         string
+	Placeholder bool
+	External    bool
+	Parent      Issue
+	Assignee    User
+	Reporter    User
+	Resolution  Resolution
+}
+
+// nodeLabel is a parsed node label template. Only one of text and html is set.
+type nodeLabel struct {
+	text *template.Template
+	// A graphviz HTML-like label, with the values escaped for HTML.
+	html *htmltemplate.Template
+	lut  map[string]int // See CustomfieldValue.
+}
+
+// parseNodeLabel parses the node label template 'tmpl', a Go text/template
+// with the functions of labelFuncs. If 'html', the template is a graphviz
+// HTML-like label, parsed as a Go html/template so that the values are
+// escaped. Custom fields are looked up by name in 'lut'.
+func parseNodeLabel(tmpl string, html bool, lut map[string]int) (*nodeLabel, error) {
+	// Function cf is replaced for each issue, see executeNode.
+	funcs := labelFuncs(Issue{}, lut)
+	label := &nodeLabel{lut: lut}
+	var err error
+	if html {
+		label.html, err = htmltemplate.New("node-label").Funcs(funcs).Parse(tmpl)
+	} else {
+		label.text, err = template.New("node-label").Funcs(funcs).Parse(tmpl)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("node label: %s", err)
+	}
+	return label, nil
+}
+
+// labelFuncs returns the functions available to the node label template of
+// 'ticket':
+//
+//   - cf NAME: the value of custom field NAME in 'lut' (the values of a
+//     multi-valued field are separated by ", ").
+//   - shorten WIDTH S: S shortened in the middle to WIDTH characters.
+//   - wrap WIDTH S: S wrapped on lines of at most WIDTH characters.
+//   - date LAYOUT D: date D formatted with the Go time LAYOUT (empty if unset).
+//   - join SEP LIST: the elements of LIST separated by SEP.
+func labelFuncs(ticket Issue, lut map[string]int) map[string]any {
+	return map[string]any{
+		"cf": func(name string) (string, error) {
+			if _, found := lut[name]; !found {
+				return "", fmt.Errorf("cf: %q is not in --custom-fields", name)
+			}
+			cf := lookupCustomField(ticket.Fields.CustomFields, lut, name)
+			return strings.Join(cf.Values(), ", "), nil
+		},
+		"shorten": func(width int, s string) string {
+			return text.ShortenMiddle(s, width)
+		},
+		"wrap": func(width int, s string) string {
+			return text.Wrap(s, width)
+		},
+		"date": func(layout string, date any) (string, error) {
+			var t time.Time
+			switch d := date.(type) {
+			case Date:
+				t = d.Time
+			case DateTime:
+				t = d.Time
+			case time.Time:
+				t = d
+			default:
+				return "", fmt.Errorf("date: %T is not a date", date)
+			}
+			if t.IsZero() {
+				return "", nil
+			}
+			return t.Format(layout), nil
+		},
+		"join": func(sep string, list []string) string {
+			return strings.Join(list, sep)
+		},
+	}
+}
+
+// executeNode returns the label of 'node'.
+func (l *nodeLabel) executeNode(node *Node) (string, error) {
+	ticket := node.Issue
+	fields := ticket.Fields
+	data := labelData{
+		Fields:      fields,
+		Key:         ticket.Key,
+		Placeholder: node.Placeholder,
+		External:    node.External,
+	}
+	if fields.Parent != nil {
+		data.Parent = *fields.Parent
+	}
+	if fields.Assignee != nil {
+		data.Assignee = *fields.Assignee
+	}
+	if fields.Reporter != nil {
+		data.Reporter = *fields.Reporter
+	}
+	if fields.Resolution != nil {
+		data.Resolution = *fields.Resolution
+	}
+
+	var bld strings.Builder
+	var err error
+	funcs := labelFuncs(ticket, l.lut)
+	if l.html != nil {
+		var tmpl *htmltemplate.Template
+		if tmpl, err = l.html.Clone(); err == nil {
+			err = tmpl.Funcs(funcs).Execute(&bld, data)
+		}
+	} else {
+		var tmpl *template.Template
+		if tmpl, err = l.text.Clone(); err == nil {
+			err = tmpl.Funcs(funcs).Execute(&bld, data)
+		}
+	}
+	if err != nil {
+		return "", fmt.Errorf("node label of %s: %s", ticket.Key, err)
+	}
+	return bld.String(), nil
+}
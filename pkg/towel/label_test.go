@@ -0,0 +1,107 @@
+package towel
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marco-m/rosina"
+)
+
+func TestNodeLabel(t *testing.T) {
+	type testCase struct {
+		name string
+		tmpl string
+		html bool
+		want string
+	}
+
+	ticket := newIssue("A-1", "To Do")
+	ticket.Fields.Summary = `Peel the "mango" & slice it`
+	ticket.Fields.Assignee = &User{DisplayName: "Alice"}
+	ticket.Fields.Labels = []string{"fruit", "sweet"}
+	ticket.Fields.Duedate = Date{time.Date(2024, 10, 15, 0, 0, 0, 0, time.UTC)}
+	ticket.Fields.CustomFields = map[string]CustomField{
+		"customfield_11919": CustomField(json.RawMessage(`{"value": "Dessert"}`)),
+	}
+	lut := map[string]int{"product": 11919}
+
+	testCases := []testCase{
+		{
+			name: "default",
+			tmpl: defaultNodeLabel,
+			want: "Peel the \"mango\" & slice it\nA-1 To Do",
+		},
+		{
+			name: "fields and custom fields",
+			tmpl: `{{.Key}} {{.Assignee.DisplayName}} {{cf "product"}}`,
+			want: "A-1 Alice Dessert",
+		},
+		{
+			name: "nil pointers are empty",
+			tmpl: `{{.Reporter.DisplayName}}|{{.Parent.Key}}|{{.Resolution.Name}}`,
+			want: "||",
+		},
+		{
+			name: "helpers",
+			tmpl: `{{wrap 12 .Summary}} {{shorten 5 .Key}} {{join "+" .Labels}} {{date "Jan 2" .Duedate}}|{{date "Jan 2" .Created}}`,
+			want: "Peel the\n\"mango\" &\nslice it A-1 fruit+sweet Oct 15|",
+		},
+		{
+			name: "html",
+			tmpl: `<b>{{.Key}}</b><br/>{{.Summary}}`,
+			html: true,
+			want: "<b>A-1</b><br/>Peel the &#34;mango&#34; &amp; slice it",
+		},
+	}
+
+	test := func(t *testing.T, tc testCase) {
+		label, err := parseNodeLabel(tc.tmpl, tc.html, lut)
+		rosina.AssertNoError(t, err)
+
+		have, err := label.executeNode(&Node{Key: ticket.Key, Issue: ticket})
+		rosina.AssertNoError(t, err)
+
+		rosina.AssertEqual(t, have, tc.want, "label")
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) { test(t, tc) })
+	}
+}
+
+func TestNodeLabelErrors(t *testing.T) {
+	_, err := parseNodeLabel(`{{.Key`, false, nil)
+	rosina.AssertEqual(t, err != nil, true, "parse error")
+
+	label, err := parseNodeLabel(`{{cf "product"}}`, false, nil)
+	rosina.AssertNoError(t, err)
+	_, err = label.executeNode(&Node{Key: "A-1", Issue: newIssue("A-1", "To Do")})
+	rosina.AssertEqual(t,
+		err != nil && strings.Contains(err.Error(), `"product" is not in --custom-fields`),
+		true, "unknown custom field")
+}
+
+func TestDotRendererEscapesLabels(t *testing.T) {
+	ticket := newIssue("A-1", "To Do")
+	ticket.Fields.Summary = `a "quoted" \N summary`
+	g := NewGraph()
+	g.AddIssue(ticket)
+
+	for _, html := range []bool{false, true} {
+		tmpl := `{{.Summary}}`
+		want := `"A-1" [label="a \"quoted\" \\N summary" fillcolor="cadetblue1"]`
+		if html {
+			want = `"A-1" [label=<a &#34;quoted&#34; \N summary> fillcolor="cadetblue1"]`
+		}
+		label, err := parseNodeLabel(tmpl, html, nil)
+		rosina.AssertNoError(t, err)
+
+		var bld strings.Builder
+		err = dotRenderer{rankdir: "LR", nodeLabel: label}.Render(&bld, g)
+		rosina.AssertNoError(t, err)
+
+		rosina.AssertEqual(t, strings.Contains(bld.String(), want), true, want)
+	}
+}
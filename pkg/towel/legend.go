@@ -0,0 +1,72 @@
+package towel
+
+import (
+	"fmt"
+	"strings"
+)
+
+// legendEntries collects the styles of the theme used while rendering, to
+// explain them in a legend. A rule is collected only once, and only if it
+// sets some attribute. Rules with the same style but different labels (for
+// example the issue types "bug" and "story") are all kept, since each
+// explains a different element.
+type legendEntries struct {
+	nodes []nodeRule
+	edges []edgeRule
+	seen  map[string]bool
+}
+
+func (l *legendEntries) addNode(rules ...nodeRule) {
+	for _, rule := range rules {
+		if l.see("node", rule.label, rule.style.attrs()) {
+			l.nodes = append(l.nodes, rule)
+		}
+	}
+}
+
+func (l *legendEntries) addEdge(rules ...edgeRule) {
+	for _, rule := range rules {
+		if l.see("edge", rule.label, rule.style.attrs()) {
+			l.edges = append(l.edges, rule)
+		}
+	}
+}
+
+// see returns true if 'attrs' is not empty and the pair 'label', 'attrs' has
+// not been seen before for elements of 'kind'.
+func (l *legendEntries) see(kind string, label string, attrs string) bool {
+	if l.seen == nil {
+		l.seen = make(map[string]bool)
+	}
+	id := fmt.Sprintf("%s %q %s", kind, label, attrs)
+	if attrs == "" || l.seen[id] {
+		return false
+	}
+	l.seen[id] = true
+	return true
+}
+
+// write writes the legend as a cluster: a node per node style and an edge,
+// between two invisible nodes, per edge style. It writes nothing if there is
+// nothing to explain.
+func (l *legendEntries) write(bld *strings.Builder, indent string) {
+	if len(l.nodes) == 0 && len(l.edges) == 0 {
+		return
+	}
+	fmt.Fprintf(bld, "%ssubgraph cluster_legend {\n", indent)
+	fmt.Fprintf(bld, "%s    label=\"legend\"\n", indent)
+	for _, rule := range l.nodes {
+		attrs := dotAttrs("label", rule.label) + " " + rule.style.attrs()
+		fmt.Fprintf(bld, "%s    %q [%s]\n", indent, "legend: "+rule.label, attrs)
+	}
+	point := dotAttrs("shape", "point", "style", "invis", "width", "0.01", "height", "0.01")
+	for _, rule := range l.edges {
+		from := "legend: " + rule.label + " (from)"
+		to := "legend: " + rule.label + " (to)"
+		fmt.Fprintf(bld, "%s    %q [%s]\n", indent, from, point)
+		fmt.Fprintf(bld, "%s    %q [%s]\n", indent, to, point)
+		attrs := dotAttrs("label", rule.label) + " " + rule.style.attrs()
+		fmt.Fprintf(bld, "%s    %q -> %q [%s]\n", indent, from, to, attrs)
+	}
+	fmt.Fprintf(bld, "%s}\n", indent)
+}
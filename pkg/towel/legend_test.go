@@ -0,0 +1,65 @@
+package towel
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/marco-m/rosina"
+)
+
+func TestLegendOnlyUsedStyles(t *testing.T) {
+	// Both issues are in status category "to do": A-2, without status
+	// category, by its status name.
+	ticket := newIssue("A-1", "To Do", "A-2")
+	ticket.Fields.Status.StatusCategory = StatusCategory{Key: "new", Name: "To Do"}
+	g := NewGraph()
+	g.AddIssue(ticket)
+	g.AddIssue(newIssue("A-2", "To Do"))
+
+	render := func(legend bool) string {
+		var bld strings.Builder
+		err := dotRenderer{rankdir: "LR", legend: legend}.Render(&bld, g)
+		rosina.AssertNoError(t, err)
+		_, after, _ := strings.Cut(bld.String(), "subgraph cluster_legend {\n")
+		return after
+	}
+
+	want := `        label="legend"
+        "legend: to do" [label="to do" fillcolor="cadetblue1"]
+        "legend: blocks (from)" [shape="point" style="invis" width="0.01" height="0.01"]
+        "legend: blocks (to)" [shape="point" style="invis" width="0.01" height="0.01"]
+        "legend: blocks (from)" -> "legend: blocks (to)" [label="blocks" color="red"]
+    }
+}
+`
+	rosina.AssertEqual(t, render(true), want, "legend")
+	rosina.AssertEqual(t, render(false), "", "no legend")
+}
+
+func TestLegendSameStyleDifferentLabels(t *testing.T) {
+	theme := defaultTheme()
+	theme.IssueType = map[string]NodeStyle{
+		"bug":   {Shape: "octagon"},
+		"story": {Shape: "octagon"},
+	}
+	bug := newIssue("A-1", "")
+	bug.Fields.IssueType.Name = "Bug"
+	story := newIssue("A-2", "")
+	story.Fields.IssueType.Name = "Story"
+	g := NewGraph()
+	g.AddIssue(bug)
+	g.AddIssue(story)
+
+	var bld strings.Builder
+	err := dotRenderer{rankdir: "LR", legend: true, theme: theme}.Render(&bld, g)
+	rosina.AssertNoError(t, err)
+	_, legend, _ := strings.Cut(bld.String(), "subgraph cluster_legend {\n")
+
+	want := `        label="legend"
+        "legend: issue type bug" [label="issue type bug" shape="octagon"]
+        "legend: issue type story" [label="issue type story" shape="octagon"]
+    }
+}
+`
+	rosina.AssertEqual(t, legend, want, "legend")
+}
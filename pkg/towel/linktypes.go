@@ -0,0 +1,50 @@
+package towel
+
+import (
+	"fmt"
+	"strings"
+)
+
+// linkTypeFilter returns a function accepting the link types of one of
+// 'include' (all if empty) and not of one of 'exclude' (see matchLinkType),
+// or nil if both are empty. See Graph.setLinkFilter.
+func linkTypeFilter(include []string, exclude []string) func(LinkType) bool {
+	if len(include) == 0 && len(exclude) == 0 {
+		return nil
+	}
+	return func(linkType LinkType) bool {
+		return matchLinkType(linkType, include) &&
+			(len(exclude) == 0 || !matchLinkType(linkType, exclude))
+	}
+}
+
+// checkLinkTypes returns an error if a name in 'names' does not match any of
+// the link types 'known' of the Jira instance (see matchLinkType). The error
+// lists the known link types, to help with discovery.
+func checkLinkTypes(names []string, known []LinkType) error {
+	for _, name := range names {
+		found := false
+		for _, linkType := range known {
+			if matchLinkType(linkType, []string{name}) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("unknown link type %q; the link types are: %s",
+				name, describeLinkTypes(known))
+		}
+	}
+	return nil
+}
+
+// describeLinkTypes returns 'linkTypes' in human-readable form, for example:
+// "Blocks (blocks / is blocked by), Relates (relates to / relates to)".
+func describeLinkTypes(linkTypes []LinkType) string {
+	descriptions := make([]string, 0, len(linkTypes))
+	for _, linkType := range linkTypes {
+		descriptions = append(descriptions, fmt.Sprintf("%s (%s / %s)",
+			linkType.Name, linkType.Outward, linkType.Inward))
+	}
+	return strings.Join(descriptions, ", ")
+}
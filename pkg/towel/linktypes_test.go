@@ -0,0 +1,108 @@
+package towel
+
+import (
+	"testing"
+
+	"github.com/marco-m/rosina"
+)
+
+func TestSetLinkFilter(t *testing.T) {
+	type testCase struct {
+		name      string
+		include   []string
+		exclude   []string
+		wantNodes string
+		wantEdges string
+	}
+
+	relates := LinkType{Name: "Relates", Inward: "relates to", Outward: "relates to"}
+	duplicate := LinkType{Name: "Duplicate", Inward: "is duplicated by", Outward: "duplicates"}
+	// withLink returns 'ticket' with an outward link of 'linkType' to 'key'.
+	withLink := func(ticket Issue, linkType LinkType, key string) Issue {
+		ticket.Fields.Issuelinks = append(ticket.Fields.Issuelinks,
+			IssueLink{Type: linkType, OutwardIssue: Issue{Key: key}})
+		return ticket
+	}
+
+	testCases := []testCase{
+		{
+			name:      "no filter",
+			wantNodes: "A-1 A-2 X-1 X-2 A-3",
+			wantEdges: "A-1->A-2 A-1->X-1 A-2->X-2 A-2->A-3",
+		},
+		{
+			name:      "include",
+			include:   []string{"blocks"},
+			wantNodes: "A-1 A-2 A-3",
+			wantEdges: "A-1->A-2",
+		},
+		{
+			name:      "exclude, by name or description",
+			exclude:   []string{"Relates", "duplicates"},
+			wantNodes: "A-1 A-2 A-3",
+			wantEdges: "A-1->A-2",
+		},
+		{
+			name:      "include and exclude",
+			include:   []string{"blocks", "relates"},
+			exclude:   []string{"relates"},
+			wantNodes: "A-1 A-2 A-3",
+			wantEdges: "A-1->A-2",
+		},
+	}
+
+	test := func(t *testing.T, tc testCase) {
+		g := NewGraph()
+		g.AddIssue(withLink(newIssue("A-1", "To Do", "A-2"), relates, "X-1"))
+		if accept := linkTypeFilter(tc.include, tc.exclude); accept != nil {
+			g.setLinkFilter(accept)
+		}
+		// Added after the filter, to check that it applies also to new issues.
+		g.AddIssue(withLink(withLink(newIssue("A-2", "To Do"),
+			relates, "X-2"), duplicate, "A-3"))
+		g.AddIssue(newIssue("A-3", "To Do"))
+
+		rosina.AssertEqual(t, nodeKeys(g.Nodes()), tc.wantNodes, "nodes")
+		rosina.AssertEqual(t, edgeNames(g.Edges()), tc.wantEdges, "edges")
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) { test(t, tc) })
+	}
+}
+
+func TestSetLinkFilterRemovesInterleavedEdges(t *testing.T) {
+	relates := LinkType{Name: "Relates", Inward: "relates to", Outward: "relates to"}
+	ticket := newIssue("A-1", "To Do")
+	for _, link := range []struct {
+		linkType LinkType
+		key      string
+	}{
+		{relates, "X-1"}, {blocks, "A-2"}, {relates, "X-2"}, {blocks, "A-3"}, {relates, "X-3"},
+	} {
+		ticket.Fields.Issuelinks = append(ticket.Fields.Issuelinks,
+			IssueLink{Type: link.linkType, OutwardIssue: Issue{Key: link.key}})
+	}
+	g := NewGraph()
+	g.AddIssue(ticket)
+
+	g.setLinkFilter(linkTypeFilter([]string{"blocks"}, nil))
+
+	rosina.AssertEqual(t, nodeKeys(g.Nodes()), "A-1 A-2 A-3", "nodes")
+	rosina.AssertEqual(t, edgeNames(g.Edges()), "A-1->A-2 A-1->A-3", "edges")
+	rosina.AssertEqual(t, edgeNames(g.OutEdges("A-1")), "A-1->A-2 A-1->A-3", "out edges")
+}
+
+func TestCheckLinkTypes(t *testing.T) {
+	known := []LinkType{
+		blocks,
+		{Name: "Relates", Inward: "relates to", Outward: "relates to"},
+	}
+
+	rosina.AssertNoError(t, checkLinkTypes([]string{"Blocks", "is blocked by", "relates to"}, known))
+
+	err := checkLinkTypes([]string{"blocks", "clones"}, known)
+	rosina.AssertEqual(t, err.Error(),
+		`unknown link type "clones"; the link types are: Blocks (blocks / is blocked by), Relates (relates to / relates to)`,
+		"error")
+}
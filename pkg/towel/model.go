@@ -0,0 +1,247 @@
+package towel
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Issue is a Jira issue.
+//
+// When an issue is embedded in another one (parent, subtasks, links), Jira
+// fills only the key and a few fields, such as summary, status and issue type.
+type Issue struct {
+	Key    string `json:"key"`
+	Fields Fields `json:"fields"`
+}
+
+// Fields contains the fields of an issue. The well-known fields are typed,
+// while the custom fields are collected in CustomFields; see UnmarshalJSON.
+type Fields struct {
+	IssueType      IssueType    `json:"issuetype"`
+	Parent         *Issue       `json:"parent"`
+	Project        Project      `json:"project"`
+	Priority       Priority     `json:"priority"`
+	Labels         []string     `json:"labels"`
+	Issuelinks     []IssueLink  `json:"issuelinks"`
+	Assignee       *User        `json:"assignee"` // nil if unassigned.
+	Reporter       *User        `json:"reporter"`
+	Creator        User         `json:"creator"`
+	Status         Status       `json:"status"`
+	Resolution     *Resolution  `json:"resolution"` // nil if unresolved.
+	Description    string       `json:"description"`
+	Summary        string       `json:"summary"`
+	Subtasks       []Issue      `json:"subtasks"` // Only key and a few fields.
+	Components     []Component  `json:"components"`
+	FixVersions    []Version    `json:"fixVersions"`
+	Duedate        Date         `json:"duedate"`
+	Created        DateTime     `json:"created"`
+	Updated        DateTime     `json:"updated"`
+	ResolutionDate DateTime     `json:"resolutiondate"`
+	TimeTracking   TimeTracking `json:"timetracking"`
+	Progress       struct {
+		Progress int `json:"progress"`
+		Total    int `json:"total"`
+	} `json:"progress"`
+
+	// HACK. Sigh.
+	// I think I never found something as badly designed as Jira.
+	// Filled by UnmarshalJSON with all the "customfield_NNNNN" keys.
+	CustomFields map[string]CustomField `json:"-"`
+}
+
+// IssueLink is a link from the issue containing it to another issue.
+type IssueLink struct {
+	OutwardIssue Issue `json:"outwardIssue"`
+	InwardIssue  Issue `json:"inwardIssue"`
+	// NOTE The Type fields are always the same, for example Name = Blocks,
+	// so there is no direction information! The direction is determined by
+	// which one of OutwardIssue or InwardIssue is filled.
+	Type LinkType `json:"type"`
+}
+
+// LinkType is a type of issue link, for example:
+// Name: "Blocks", Inward: "is blocked by", Outward: "blocks".
+type LinkType struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Inward  string `json:"inward"`
+	Outward string `json:"outward"`
+}
+
+type Project struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
+}
+
+type IssueType struct {
+	Name    string `json:"name"`
+	Subtask bool   `json:"subtask"`
+}
+
+type Status struct {
+	Name           string         `json:"name"`
+	StatusCategory StatusCategory `json:"statusCategory"`
+}
+
+// Priority is the priority of an issue. With the default priority scheme, a
+// lower ID is a higher priority.
+type Priority struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// StatusCategory groups the statuses of all the workflows. Key is one of
+// "new", "indeterminate" or "done".
+type StatusCategory struct {
+	ID        int    `json:"id"`
+	Key       string `json:"key"`
+	Name      string `json:"name"`
+	ColorName string `json:"colorName"`
+}
+
+// isDone returns true if 'ticket' is finished. It uses the status category
+// if available, otherwise the resolution or the status name.
+func isDone(ticket Issue) bool {
+	status := ticket.Fields.Status
+	if status.StatusCategory.Key != "" {
+		return status.StatusCategory.Key == "done"
+	}
+	return ticket.Fields.Resolution != nil || strings.EqualFold(status.Name, "done")
+}
+
+// User is a Jira user (assignee, reporter, creator, ...).
+// Depending on the privacy settings of the user, EmailAddress might be empty.
+type User struct {
+	AccountID    string `json:"accountId"`
+	DisplayName  string `json:"displayName"`
+	EmailAddress string `json:"emailAddress"`
+	Active       bool   `json:"active"`
+}
+
+// Component is a project component.
+type Component struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Version is a project version, as used by "fixVersions".
+type Version struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Released    bool   `json:"released"`
+	Archived    bool   `json:"archived"`
+	ReleaseDate Date   `json:"releaseDate"`
+}
+
+// Resolution is the resolution of an issue, for example "Done" or "Won't Do".
+type Resolution struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// TimeTracking contains the estimates of an issue. The string fields are in
+// the Jira human format (for example "2h 30m"); the Seconds fields contain the
+// same information in seconds, and are zero if not set.
+type TimeTracking struct {
+	OriginalEstimate         string `json:"originalEstimate"`
+	RemainingEstimate        string `json:"remainingEstimate"`
+	TimeSpent                string `json:"timeSpent"`
+	OriginalEstimateSeconds  int    `json:"originalEstimateSeconds"`
+	RemainingEstimateSeconds int    `json:"remainingEstimateSeconds"`
+	TimeSpentSeconds         int    `json:"timeSpentSeconds"`
+}
+
+// Jira formats for dates and datetimes.
+const (
+	dateLayout     = "2006-01-02"
+	dateTimeLayout = "2006-01-02T15:04:05.000-0700"
+)
+
+// Date is a Jira date without time, such as field "duedate" ("2024-10-15").
+// The zero value (IsZero() == true) means that the date is not set.
+type Date struct {
+	time.Time
+}
+
+func (d *Date) UnmarshalJSON(data []byte) error {
+	s, err := unmarshalTimeString(data)
+	if err != nil || s == "" {
+		*d = Date{}
+		return err
+	}
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		return fmt.Errorf("date: %s", err)
+	}
+	*d = Date{t}
+	return nil
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(d.Format(dateLayout))
+}
+
+func (d Date) String() string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.Format(dateLayout)
+}
+
+// DateTime is a Jira datetime, such as field "created"
+// ("2024-09-01T09:00:00.000+0200").
+// The zero value (IsZero() == true) means that the datetime is not set.
+type DateTime struct {
+	time.Time
+}
+
+func (dt *DateTime) UnmarshalJSON(data []byte) error {
+	s, err := unmarshalTimeString(data)
+	if err != nil || s == "" {
+		*dt = DateTime{}
+		return err
+	}
+	t, err := time.Parse(dateTimeLayout, s)
+	if err != nil {
+		// Some fields (for example the sprint dates) use RFC 3339 instead.
+		t, err = time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return fmt.Errorf("datetime: %s", err)
+		}
+	}
+	*dt = DateTime{t}
+	return nil
+}
+
+func (dt DateTime) MarshalJSON() ([]byte, error) {
+	if dt.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(dt.Format(dateTimeLayout))
+}
+
+func (dt DateTime) String() string {
+	if dt.IsZero() {
+		return ""
+	}
+	return dt.Format(dateTimeLayout)
+}
+
+// unmarshalTimeString returns the JSON string in 'data', or the empty string
+// if 'data' is null.
+func unmarshalTimeString(data []byte) (string, error) {
+	if string(bytes.TrimSpace(data)) == "null" {
+		return "", nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return "", err
+	}
+	return s, nil
+}
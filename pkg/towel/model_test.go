@@ -0,0 +1,96 @@
+package towel
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/marco-m/rosina"
+)
+
+func TestDateJSON(t *testing.T) {
+	type testCase struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}
+
+	testCases := []testCase{
+		{name: "date", input: `"2024-10-15"`, want: "2024-10-15"},
+		{name: "null", input: `null`, want: ""},
+		{name: "empty string", input: `""`, want: ""},
+		{name: "datetime is not a date", input: `"2024-10-15T10:00:00.000+0200"`,
+			wantErr: true},
+		{name: "not a string", input: `20241015`, wantErr: true},
+	}
+
+	test := func(t *testing.T, tc testCase) {
+		var have Date
+		err := json.Unmarshal([]byte(tc.input), &have)
+		if tc.wantErr {
+			rosina.AssertEqual(t, err != nil, true, "error")
+			return
+		}
+		rosina.AssertNoError(t, err)
+		rosina.AssertEqual(t, have.String(), tc.want, "date")
+
+		buf, err := json.Marshal(have)
+		rosina.AssertNoError(t, err)
+		var roundTrip Date
+		err = json.Unmarshal(buf, &roundTrip)
+		rosina.AssertNoError(t, err)
+		rosina.AssertEqual(t, roundTrip.String(), tc.want, "round trip")
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) { test(t, tc) })
+	}
+}
+
+func TestDateTimeJSON(t *testing.T) {
+	type testCase struct {
+		name    string
+		input   string
+		want    time.Time
+		wantErr bool
+	}
+
+	zurich := time.FixedZone("", 2*60*60)
+	testCases := []testCase{
+		{
+			name:  "jira format",
+			input: `"2024-09-01T09:00:00.000+0200"`,
+			want:  time.Date(2024, 9, 1, 9, 0, 0, 0, zurich),
+		},
+		{
+			name:  "RFC 3339",
+			input: `"2024-09-02T07:00:00.000Z"`,
+			want:  time.Date(2024, 9, 2, 7, 0, 0, 0, time.UTC),
+		},
+		{name: "null", input: `null`},
+		{name: "date is not a datetime", input: `"2024-10-15"`, wantErr: true},
+	}
+
+	test := func(t *testing.T, tc testCase) {
+		var have DateTime
+		err := json.Unmarshal([]byte(tc.input), &have)
+		if tc.wantErr {
+			rosina.AssertEqual(t, err != nil, true, "error")
+			return
+		}
+		rosina.AssertNoError(t, err)
+		rosina.AssertEqual(t, have.Equal(tc.want), true, "datetime "+have.String())
+
+		buf, err := json.Marshal(have)
+		rosina.AssertNoError(t, err)
+		var roundTrip DateTime
+		err = json.Unmarshal(buf, &roundTrip)
+		rosina.AssertNoError(t, err)
+		rosina.AssertEqual(t, roundTrip.Equal(tc.want), true, "round trip")
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) { test(t, tc) })
+	}
+}
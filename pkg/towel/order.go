@@ -0,0 +1,113 @@
+package towel
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+)
+
+// rankField is the name of the custom field (see --custom-fields) holding the
+// rank, the order of the issues in the backlog.
+const rankField = "rank"
+
+// workOrder returns the issues of 'g' (placeholders excluded) in waves: each
+// issue is blocked (edges of 'linkTypes'; see matchLinkType) only by issues
+// of the previous waves, so the issues of a wave can be worked on in parallel.
+// Within a wave, the issues are sorted by compareWork.
+func workOrder(g *Graph, linkTypes []string, lut map[string]int) ([][]Issue, error) {
+	dependencies := func(edges []*Edge) []*Edge {
+		var deps []*Edge
+		for _, edge := range edges {
+			from, _ := g.Node(edge.From)
+			to, _ := g.Node(edge.To)
+			if matchLinkType(edge.Type, linkTypes) && !from.Placeholder &&
+				!to.Placeholder {
+				deps = append(deps, edge)
+			}
+		}
+		return deps
+	}
+
+	// Kahn's algorithm, one wave at a time.
+	inDegree := make(map[string]int)
+	var wave []Issue
+	for _, ticket := range g.Issues() {
+		inDegree[ticket.Key] = len(dependencies(g.InEdges(ticket.Key)))
+		if inDegree[ticket.Key] == 0 {
+			wave = append(wave, ticket)
+		}
+	}
+
+	compare := compareWork(lut)
+	var waves [][]Issue
+	visited := 0
+	for len(wave) > 0 {
+		slices.SortFunc(wave, compare)
+		waves = append(waves, wave)
+		visited += len(wave)
+
+		var next []Issue
+		for _, ticket := range wave {
+			for _, edge := range dependencies(g.OutEdges(ticket.Key)) {
+				inDegree[edge.To]--
+				if inDegree[edge.To] == 0 {
+					node, _ := g.Node(edge.To)
+					next = append(next, node.Issue)
+				}
+			}
+		}
+		wave = next
+	}
+	if visited < len(inDegree) {
+		return nil, errCycles
+	}
+	return waves, nil
+}
+
+// compareWork returns a function that orders issues by priority (see
+// Priority), then by rank (custom field rankField in 'lut'), then by key.
+// Issues without priority or rank come last.
+func compareWork(lut map[string]int) func(a, b Issue) int {
+	priority := func(ticket Issue) int {
+		id, err := strconv.Atoi(ticket.Fields.Priority.ID)
+		if err != nil {
+			return math.MaxInt
+		}
+		return id
+	}
+	compareRanks := func(a, b Issue) int {
+		rankA := lookupCustomField(a.Fields.CustomFields, lut, rankField).Text()
+		rankB := lookupCustomField(b.Fields.CustomFields, lut, rankField).Text()
+		switch {
+		case rankA == rankB:
+			return 0
+		case rankA == "":
+			return 1
+		case rankB == "":
+			return -1
+		default:
+			return cmp.Compare(rankA, rankB)
+		}
+	}
+	return func(a, b Issue) int {
+		return cmp.Or(
+			cmp.Compare(priority(a), priority(b)),
+			compareRanks(a, b),
+			compareKeys(a.Key, b.Key))
+	}
+}
+
+// printWaves prints 'waves' in human-readable form.
+func printWaves(w io.Writer, waves [][]Issue) {
+	for i, wave := range waves {
+		fmt.Fprintf(w, "wave %d (%d issues):\n", i+1, len(wave))
+		for _, ticket := range wave {
+			fmt.Fprintf(w, "  %s (%s) %s\n", ticket.Key,
+				cmp.Or(ticket.Fields.Priority.Name, "no priority"),
+				ticket.Fields.Summary)
+		}
+	}
+}
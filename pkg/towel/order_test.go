@@ -0,0 +1,122 @@
+package towel
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/marco-m/rosina"
+)
+
+func TestWorkOrder(t *testing.T) {
+	type testCase struct {
+		name   string
+		issues []Issue
+		want   string
+	}
+
+	with := func(ticket Issue, priority string, rank string) Issue {
+		ticket.Fields.Priority = Priority{ID: priority, Name: "P" + priority}
+		if rank != "" {
+			ticket.Fields.CustomFields = map[string]CustomField{
+				"customfield_10019": CustomField(json.RawMessage(`"` + rank + `"`)),
+			}
+		}
+		return ticket
+	}
+
+	testCases := []testCase{
+		{
+			name: "empty",
+			want: "",
+		},
+		{
+			name: "chain and independent issues",
+			issues: []Issue{
+				newIssue("A-1", "To Do", "A-2"),
+				newIssue("A-2", "To Do", "A-3"),
+				newIssue("A-3", "To Do"),
+				newIssue("A-4", "To Do"),
+			},
+			want: "A-1 A-4 | A-2 | A-3",
+		},
+		{
+			name: "an issue waits for all its blockers",
+			issues: []Issue{
+				newIssue("A-1", "To Do", "A-2", "A-3"),
+				newIssue("A-2", "To Do", "A-3"),
+				newIssue("A-3", "To Do"),
+			},
+			want: "A-1 | A-2 | A-3",
+		},
+		{
+			name: "placeholders are ignored",
+			issues: []Issue{
+				newIssue("A-1", "To Do", "X-1"),
+			},
+			want: "A-1",
+		},
+		{
+			name: "tie-breaking by priority, then rank, then key",
+			issues: []Issue{
+				with(newIssue("A-1", "To Do"), "", ""),
+				with(newIssue("A-2", "To Do"), "3", ""),
+				with(newIssue("A-3", "To Do"), "3", "0|i0000b:"),
+				with(newIssue("A-4", "To Do"), "3", "0|i0000a:"),
+				with(newIssue("A-5", "To Do"), "1", ""),
+				with(newIssue("A-10", "To Do"), "", ""),
+			},
+			want: "A-5 A-4 A-3 A-2 A-1 A-10",
+		},
+	}
+
+	test := func(t *testing.T, tc testCase) {
+		g := NewGraph()
+		for _, ticket := range tc.issues {
+			g.AddIssue(ticket)
+		}
+
+		waves, err := workOrder(g, dependencyLinkTypes, map[string]int{"rank": 10019})
+		rosina.AssertNoError(t, err)
+
+		var have []string
+		for _, wave := range waves {
+			var keys []string
+			for _, ticket := range wave {
+				keys = append(keys, ticket.Key)
+			}
+			have = append(have, strings.Join(keys, " "))
+		}
+		rosina.AssertEqual(t, strings.Join(have, " | "), tc.want, "waves")
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) { test(t, tc) })
+	}
+}
+
+func TestWorkOrderCycles(t *testing.T) {
+	g := NewGraph()
+	g.AddIssue(newIssue("A-1", "To Do", "A-2"))
+	g.AddIssue(newIssue("A-2", "To Do", "A-1"))
+	g.AddIssue(newIssue("A-3", "To Do"))
+
+	_, err := workOrder(g, dependencyLinkTypes, nil)
+
+	rosina.AssertEqual(t, err, errCycles, "error")
+}
+
+func TestPrintWaves(t *testing.T) {
+	ticket := newIssue("A-1", "To Do")
+	ticket.Fields.Priority = Priority{ID: "2", Name: "High"}
+	var bld strings.Builder
+
+	printWaves(&bld, [][]Issue{{ticket}, {newIssue("A-2", "To Do")}})
+
+	want := `wave 1 (1 issues):
+  A-1 (High) summary of A-1
+wave 2 (1 issues):
+  A-2 (no priority) summary of A-2
+`
+	rosina.AssertEqual(t, bld.String(), want, "printWaves")
+}
@@ -0,0 +1,103 @@
+package towel
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// redundantLink is an edge implied by a longer path.
+type redundantLink struct {
+	Edge *Edge
+	// Path is the longer path from Edge.From to Edge.To, both included.
+	Path []string
+}
+
+// redundantEdges returns the edges of 'g' (of 'linkTypes'; see matchLinkType)
+// that are implied by a longer path made of edges of 'linkTypes': for example,
+// given A blocks B, B blocks C, then A blocks C is redundant.
+//
+// Edges touching an issue that is part of a dependency cycle are never
+// redundant, because the transitive reduction is well-defined only for
+// directed acyclic graphs.
+func redundantEdges(g *Graph, linkTypes []string) []redundantLink {
+	inCycle := make(map[string]bool)
+	for _, cycle := range findCycles(g, linkTypes) {
+		for _, key := range cycle {
+			inCycle[key] = true
+		}
+	}
+
+	var redundant []redundantLink
+	for _, edge := range g.Edges() {
+		if !matchLinkType(edge.Type, linkTypes) || inCycle[edge.From] ||
+			inCycle[edge.To] {
+			continue
+		}
+		if path := longerPath(g, edge, linkTypes); path != nil {
+			redundant = append(redundant, redundantLink{Edge: edge, Path: path})
+		}
+	}
+	return redundant
+}
+
+// longerPath returns a path of at least two edges of 'linkTypes' from
+// edge.From to edge.To, or nil if there is none. The path is the shortest one,
+// found with a breadth-first search.
+func longerPath(g *Graph, edge *Edge, linkTypes []string) []string {
+	prev := map[string]string{edge.From: ""}
+	var queue []string
+	for _, out := range g.OutEdges(edge.From) {
+		if out.To == edge.To || !matchLinkType(out.Type, linkTypes) {
+			continue
+		}
+		if _, seen := prev[out.To]; !seen {
+			prev[out.To] = edge.From
+			queue = append(queue, out.To)
+		}
+	}
+
+	for len(queue) > 0 {
+		key := queue[0]
+		queue = queue[1:]
+		for _, out := range g.OutEdges(key) {
+			if !matchLinkType(out.Type, linkTypes) {
+				continue
+			}
+			if _, seen := prev[out.To]; seen {
+				continue
+			}
+			prev[out.To] = key
+			if out.To == edge.To {
+				var path []string
+				for k := edge.To; k != ""; k = prev[k] {
+					path = append([]string{k}, path...)
+				}
+				return path
+			}
+			queue = append(queue, out.To)
+		}
+	}
+	return nil
+}
+
+// reduceGraph removes from 'g' the edges of 'redundant'.
+func reduceGraph(g *Graph, redundant []redundantLink) {
+	for _, link := range redundant {
+		g.removeEdge(link.Edge)
+	}
+}
+
+// printRedundant prints 'redundant' in human-readable form, as the Jira links
+// to remove.
+func printRedundant(w io.Writer, redundant []redundantLink) {
+	if len(redundant) == 0 {
+		fmt.Fprintln(w, "no redundant links")
+		return
+	}
+	fmt.Fprintf(w, "redundant links (%d):\n", len(redundant))
+	for _, link := range redundant {
+		fmt.Fprintf(w, "  %s %s %s, implied by %s\n", link.Edge.From,
+			link.Edge.Type.Outward, link.Edge.To, strings.Join(link.Path, " -> "))
+	}
+}
@@ -0,0 +1,113 @@
+package towel
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/marco-m/rosina"
+)
+
+func TestRedundantEdges(t *testing.T) {
+	type testCase struct {
+		name          string
+		issues        []Issue
+		wantRedundant string
+		wantReduced   string
+	}
+
+	testCases := []testCase{
+		{
+			name: "nothing to reduce",
+			issues: []Issue{
+				newIssue("A-1", "To Do", "A-2", "A-3"),
+				newIssue("A-2", "To Do", "A-4"),
+			},
+			wantRedundant: "",
+			wantReduced:   "A-1->A-2 A-1->A-3 A-2->A-4",
+		},
+		{
+			name: "triangle",
+			issues: []Issue{
+				newIssue("A-1", "To Do", "A-2", "A-3"),
+				newIssue("A-2", "To Do", "A-3"),
+			},
+			wantRedundant: "A-1->A-3",
+			wantReduced:   "A-1->A-2 A-2->A-3",
+		},
+		{
+			name: "long chain implies all the shortcuts",
+			issues: []Issue{
+				newIssue("A-1", "To Do", "A-2", "A-3", "A-4"),
+				newIssue("A-2", "To Do", "A-3", "A-4"),
+				newIssue("A-3", "To Do", "A-4"),
+			},
+			wantRedundant: "A-1->A-3 A-1->A-4 A-2->A-4",
+			wantReduced:   "A-1->A-2 A-2->A-3 A-3->A-4",
+		},
+		{
+			name: "edges touching a cycle are kept",
+			issues: []Issue{
+				newIssue("A-1", "To Do", "A-2", "A-3"),
+				newIssue("A-2", "To Do", "A-3"),
+				newIssue("A-3", "To Do", "A-2"),
+			},
+			wantRedundant: "",
+			wantReduced:   "A-1->A-2 A-1->A-3 A-2->A-3 A-3->A-2",
+		},
+	}
+
+	test := func(t *testing.T, tc testCase) {
+		g := NewGraph()
+		for _, ticket := range tc.issues {
+			g.AddIssue(ticket)
+		}
+
+		redundant := redundantEdges(g, dependencyLinkTypes)
+		var edges []*Edge
+		for _, link := range redundant {
+			edges = append(edges, link.Edge)
+		}
+		rosina.AssertEqual(t, edgeNames(edges), tc.wantRedundant, "redundant")
+
+		reduceGraph(g, redundant)
+		rosina.AssertEqual(t, edgeNames(g.Edges()), tc.wantReduced, "reduced")
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) { test(t, tc) })
+	}
+}
+
+func TestReduceGraphKeepsOtherLinkTypes(t *testing.T) {
+	relates := LinkType{Name: "Relates", Inward: "relates to", Outward: "relates to"}
+	ticket := newIssue("A-1", "To Do", "A-2")
+	ticket.Fields.Issuelinks = append(ticket.Fields.Issuelinks,
+		IssueLink{Type: relates, OutwardIssue: Issue{Key: "A-3"}})
+	g := NewGraph()
+	g.AddIssue(ticket)
+	g.AddIssue(newIssue("A-2", "To Do", "A-3"))
+
+	reduceGraph(g, redundantEdges(g, dependencyLinkTypes))
+
+	rosina.AssertEqual(t, edgeNames(g.Edges()), "A-1->A-2 A-1->A-3 A-2->A-3",
+		"edges")
+	rosina.AssertEqual(t, edgeNames(g.OutEdges("A-1")), "A-1->A-2 A-1->A-3",
+		"out edges of A-1")
+}
+
+func TestPrintRedundant(t *testing.T) {
+	g := NewGraph()
+	g.AddIssue(newIssue("A-1", "To Do", "A-2", "A-3"))
+	g.AddIssue(newIssue("A-2", "To Do", "A-3"))
+	var bld strings.Builder
+
+	printRedundant(&bld, redundantEdges(g, dependencyLinkTypes))
+
+	want := `redundant links (1):
+  A-1 blocks A-3, implied by A-1 -> A-2 -> A-3
+`
+	rosina.AssertEqual(t, bld.String(), want, "printRedundant")
+
+	g.removeEdge(g.OutEdges("A-1")[1])
+	rosina.AssertEqual(t, edgeNames(g.InEdges("A-3")), "A-2->A-3", "in edges of A-3")
+}
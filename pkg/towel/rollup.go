@@ -0,0 +1,56 @@
+package towel
+
+// rollupByEpic returns a new Graph where each issue of 'g' is collapsed into
+// its epic (the root of its hierarchy, see parentOf), and each edge between
+// issues of different epics becomes an edge between the epics. Edges of the
+// same link type between the same epics are merged into one edge, with the
+// underlying edges in Edge.Links. Issues without a parent stay as they are.
+//
+// An epic that is not in 'g' becomes a placeholder, with the partial
+// information from its children.
+func rollupByEpic(g *Graph, lut map[string]int) *Graph {
+	epicOf := func(key string) Issue {
+		node, _ := g.Node(key)
+		ticket := node.Issue
+		// Jira forbids loops in the hierarchy, but better safe than sorry.
+		visited := map[string]bool{key: true}
+		for {
+			parent, found := parentOf(ticket, lut)
+			if !found || visited[parent.Key] {
+				return ticket
+			}
+			visited[parent.Key] = true
+			ticket = parent
+			if node, found := g.Node(parent.Key); found {
+				ticket = node.Issue
+			}
+		}
+	}
+
+	rollup := NewGraph()
+	epics := make(map[string]string, len(g.Nodes()))
+	for _, node := range g.Nodes() {
+		epic := epicOf(node.Key)
+		epics[node.Key] = epic.Key
+		if _, found := rollup.Node(epic.Key); found {
+			continue
+		}
+		rolled := rollup.addNode(epic.Key)
+		rolled.Issue = epic
+		if orig, found := g.Node(epic.Key); found {
+			*rolled = *orig
+		}
+	}
+
+	for _, edge := range g.Edges() {
+		from, to := epics[edge.From], epics[edge.To]
+		if from == to {
+			continue
+		}
+		rollup.addEdge(from, to, edge.Type)
+		key := edgeKey{from: from, to: to, linkType: edge.Type.Name}
+		rolled := rollup.edges[key]
+		rolled.Links = append(rolled.Links, edge)
+	}
+	return rollup
+}
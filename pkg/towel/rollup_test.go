@@ -0,0 +1,58 @@
+package towel
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/marco-m/rosina"
+)
+
+func TestRollupByEpic(t *testing.T) {
+	relates := LinkType{Name: "Relates", Inward: "relates to", Outward: "relates to"}
+	child := func(key string, parent string, blocked ...string) Issue {
+		ticket := newIssue(key, "To Do", blocked...)
+		ticket.Fields.Parent = &Issue{Key: parent}
+		ticket.Fields.Parent.Fields.Summary = "summary of " + parent
+		return ticket
+	}
+	s3 := child("S-3", "E-1", "S-5")
+	s3.Fields.Issuelinks = append(s3.Fields.Issuelinks,
+		IssueLink{Type: relates, OutwardIssue: Issue{Key: "S-4"}})
+
+	g := NewGraph()
+	g.AddIssue(newIssue("E-1", "In Progress"))
+	g.AddIssue(child("S-1", "E-1", "S-2", "S-4"))
+	g.AddIssue(child("S-2", "E-1"))
+	g.AddIssue(child("T-1", "S-1", "S-5"))
+	g.AddIssue(s3)
+	// Epic E-2 is not in the graph.
+	g.AddIssue(child("S-4", "E-2"))
+	g.AddIssue(child("S-5", "E-2"))
+	g.AddIssue(newIssue("X-1", "To Do", "S-1"))
+
+	rollup := rollupByEpic(g, nil)
+
+	rosina.AssertEqual(t, nodeKeys(rollup.Nodes()), "E-1 E-2 X-1", "nodes")
+	rosina.AssertEqual(t, edgeNames(rollup.Edges()), "E-1->E-2 E-1->E-2 X-1->E-1",
+		"edges")
+
+	e1, _ := rollup.Node("E-1")
+	rosina.AssertEqual(t, e1.Issue.Fields.Status.Name, "In Progress",
+		"epic in the graph")
+	e2, _ := rollup.Node("E-2")
+	rosina.AssertEqual(t, e2.Placeholder, true, "epic not in the graph")
+	rosina.AssertEqual(t, e2.Issue.Fields.Summary, "summary of E-2",
+		"epic from the children")
+
+	var bld strings.Builder
+	err := dotRenderer{rankdir: "LR"}.Render(&bld, rollup)
+	rosina.AssertNoError(t, err)
+	dot := bld.String()
+	for _, want := range []string{
+		`"E-1" -> "E-2" [label="blocks (3)" color="red"]`,
+		`"E-1" -> "E-2" [label="relates to (1)" style="dashed"]`,
+		`"X-1" -> "E-1" [label="blocks (1)" color="red"]`,
+	} {
+		rosina.AssertEqual(t, strings.Contains(dot, want), true, want)
+	}
+}
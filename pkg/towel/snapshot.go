@@ -0,0 +1,40 @@
+package towel
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// loadSnapshot returns the issues contained in the snapshot file 'path'.
+// A snapshot is the output of 'jira-towel query': a sequence of JSON search
+// responses, one per page. For example:
+//
+//	jira-towel query --jql 'project = MANGO' > 2024-09-16.json
+func loadSnapshot(path string) ([]Issue, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening snapshot: %s", err)
+	}
+	defer file.Close() // nolint:errcheck
+
+	var issues []Issue
+	dec := json.NewDecoder(file)
+	for pageNum := 1; ; pageNum++ {
+		var page json.RawMessage
+		if err := dec.Decode(&page); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("snapshot %s: page %d: %s", path, pageNum, err)
+		}
+		pageIssues, err := decodePage(page)
+		if err != nil {
+			return nil, fmt.Errorf("snapshot %s: page %d: %s", path, pageNum, err)
+		}
+		issues = append(issues, pageIssues...)
+	}
+	return issues, nil
+}
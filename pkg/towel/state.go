@@ -0,0 +1,67 @@
+package towel
+
+import "strings"
+
+// depState is the dependency state of an unfinished issue. An issue can be
+// both blocked and blocking, or both ready and blocking.
+type depState int
+
+const (
+	// Blocked by at least one unfinished issue.
+	stateBlocked depState = 1 << iota
+	// Blocking at least one unfinished issue.
+	stateBlocking
+	// Not blocked: it can be worked on now.
+	stateReady
+)
+
+func (s depState) String() string {
+	var names []string
+	for _, state := range []struct {
+		flag depState
+		name string
+	}{
+		{stateReady, "ready"},
+		{stateBlocked, "blocked"},
+		{stateBlocking, "blocking"},
+	} {
+		if s&state.flag != 0 {
+			names = append(names, state.name)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// dependencyStates returns the dependency state of the unfinished,
+// non-placeholder issues of 'g', following the edges of 'linkTypes' (see
+// matchLinkType) in the outward direction: "A blocks B" means that B depends
+// on A. A finished issue neither blocks nor is blocked.
+func dependencyStates(g *Graph, linkTypes []string) map[string]depState {
+	unfinished := func(key string) bool {
+		node, found := g.Node(key)
+		return found && !isDone(node.Issue)
+	}
+
+	states := make(map[string]depState)
+	for _, node := range g.Nodes() {
+		if node.Placeholder || isDone(node.Issue) {
+			continue
+		}
+		var state depState
+		for _, edge := range g.InEdges(node.Key) {
+			if matchLinkType(edge.Type, linkTypes) && unfinished(edge.From) {
+				state |= stateBlocked
+			}
+		}
+		for _, edge := range g.OutEdges(node.Key) {
+			if matchLinkType(edge.Type, linkTypes) && unfinished(edge.To) {
+				state |= stateBlocking
+			}
+		}
+		if state&stateBlocked == 0 {
+			state |= stateReady
+		}
+		states[node.Key] = state
+	}
+	return states
+}
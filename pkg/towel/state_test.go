@@ -0,0 +1,82 @@
+package towel
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/marco-m/rosina"
+)
+
+func TestDependencyStates(t *testing.T) {
+	type testCase struct {
+		name   string
+		issues []Issue
+		want   string
+	}
+
+	// blockedBy returns 'ticket' with an inward link of type 'Blocks' from
+	// 'key', so that 'key' is a placeholder if not in the graph.
+	blockedBy := func(ticket Issue, key string) Issue {
+		ticket.Fields.Issuelinks = append(ticket.Fields.Issuelinks,
+			IssueLink{Type: blocks, InwardIssue: Issue{Key: key}})
+		return ticket
+	}
+
+	testCases := []testCase{
+		{
+			name: "chain",
+			issues: []Issue{
+				newIssue("A-1", "To Do", "A-2"),
+				newIssue("A-2", "To Do", "A-3"),
+				newIssue("A-3", "To Do"),
+			},
+			want: "A-1:ready,blocking A-2:blocked,blocking A-3:blocked",
+		},
+		{
+			name: "a finished blocker does not block",
+			issues: []Issue{
+				newIssue("A-1", "Done", "A-2"),
+				newIssue("A-2", "To Do", "A-3"),
+				newIssue("A-3", "To Do"),
+			},
+			want: "A-2:ready,blocking A-3:blocked",
+		},
+		{
+			name: "a finished issue is not blocked",
+			issues: []Issue{
+				newIssue("A-1", "To Do", "A-2"),
+				newIssue("A-2", "Done"),
+			},
+			want: "A-1:ready",
+		},
+		{
+			name: "placeholders block but have no state",
+			issues: []Issue{
+				blockedBy(newIssue("A-1", "To Do"), "X-1"),
+			},
+			want: "A-1:blocked",
+		},
+	}
+
+	test := func(t *testing.T, tc testCase) {
+		g := NewGraph()
+		for _, ticket := range tc.issues {
+			g.AddIssue(ticket)
+		}
+
+		states := dependencyStates(g, dependencyLinkTypes)
+
+		var have []string
+		for _, key := range slices.SortedFunc(maps.Keys(states), compareKeys) {
+			have = append(have, fmt.Sprintf("%s:%s", key, states[key]))
+		}
+		rosina.AssertEqual(t, strings.Join(have, " "), tc.want, "states")
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) { test(t, tc) })
+	}
+}
@@ -0,0 +1,407 @@
+package towel
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Theme is the style of the graph. Nodes are styled according to the status
+// category, status, issue type and priority of their issue, in this order (a
+// later match overrides the style attributes of an earlier one). Map keys are
+// matched case-insensitively; keys of LinkType are matched as in
+// matchLinkType.
+//
+// Attribute names and values are the graphviz ones; see
+// https://graphviz.org/doc/info/attrs.html.
+type Theme struct {
+	Node           NodeStyle            `json:"node"` // Defaults for all nodes.
+	Edge           EdgeStyle            `json:"edge"` // Defaults for all edges.
+	StatusCategory map[string]NodeStyle `json:"statusCategory"`
+	Status         map[string]NodeStyle `json:"status"`
+	IssueType      map[string]NodeStyle `json:"issueType"`
+	Priority       map[string]NodeStyle `json:"priority"`
+	LinkType       map[string]EdgeStyle `json:"linkType"`
+	// Issues not in the search result, known only from a link.
+	Placeholder NodeStyle `json:"placeholder"`
+	// Issues not in the search result, fetched by following the links.
+	External NodeStyle `json:"external"`
+	// Edges part of a dependency cycle.
+	Cycle EdgeStyle `json:"cycle"`
+	// Edges part of the critical path.
+	Critical EdgeStyle `json:"critical"`
+	// Edges going through hidden issues; see hideIssues.
+	Contracted EdgeStyle `json:"contracted"`
+	// Unfinished issues blocked by an unfinished issue; see dependencyStates.
+	Blocked NodeStyle `json:"blocked"`
+	// Unfinished issues blocking an unfinished issue.
+	Blocking NodeStyle `json:"blocking"`
+	// Unfinished issues not blocked.
+	Ready NodeStyle `json:"ready"`
+}
+
+// NodeStyle is the style of a node. Empty attributes are not set.
+type NodeStyle struct {
+	FillColor string `json:"fillcolor,omitempty"`
+	Color     string `json:"color,omitempty"` // Border color.
+	PenWidth  string `json:"penwidth,omitempty"`
+	Shape     string `json:"shape,omitempty"`
+	Style     string `json:"style,omitempty"`
+	Width     string `json:"width,omitempty"`
+	Height    string `json:"height,omitempty"`
+	FixedSize string `json:"fixedsize,omitempty"`
+	FontName  string `json:"fontname,omitempty"`
+	FontColor string `json:"fontcolor,omitempty"`
+	FontSize  string `json:"fontsize,omitempty"`
+}
+
+// EdgeStyle is the style of an edge. Empty attributes are not set.
+type EdgeStyle struct {
+	Color     string `json:"color,omitempty"`
+	PenWidth  string `json:"penwidth,omitempty"`
+	Style     string `json:"style,omitempty"`
+	ArrowHead string `json:"arrowhead,omitempty"`
+	FontName  string `json:"fontname,omitempty"`
+	FontColor string `json:"fontcolor,omitempty"`
+	FontSize  string `json:"fontsize,omitempty"`
+}
+
+// merge returns 's' with the non-empty attributes of 'over'.
+func (s NodeStyle) merge(over NodeStyle) NodeStyle {
+	return NodeStyle{
+		FillColor: cmp.Or(over.FillColor, s.FillColor),
+		Color:     cmp.Or(over.Color, s.Color),
+		PenWidth:  cmp.Or(over.PenWidth, s.PenWidth),
+		Shape:     cmp.Or(over.Shape, s.Shape),
+		Style:     cmp.Or(over.Style, s.Style),
+		Width:     cmp.Or(over.Width, s.Width),
+		Height:    cmp.Or(over.Height, s.Height),
+		FixedSize: cmp.Or(over.FixedSize, s.FixedSize),
+		FontName:  cmp.Or(over.FontName, s.FontName),
+		FontColor: cmp.Or(over.FontColor, s.FontColor),
+		FontSize:  cmp.Or(over.FontSize, s.FontSize),
+	}
+}
+
+// attrs returns the non-empty attributes, in DOT syntax.
+func (s NodeStyle) attrs() string {
+	return dotAttrs(
+		"fillcolor", s.FillColor,
+		"color", s.Color,
+		"penwidth", s.PenWidth,
+		"shape", s.Shape,
+		"style", s.Style,
+		"width", s.Width,
+		"height", s.Height,
+		"fixedsize", s.FixedSize,
+		"fontname", s.FontName,
+		"fontcolor", s.FontColor,
+		"fontsize", s.FontSize)
+}
+
+// merge returns 's' with the non-empty attributes of 'over'.
+func (s EdgeStyle) merge(over EdgeStyle) EdgeStyle {
+	return EdgeStyle{
+		Color:     cmp.Or(over.Color, s.Color),
+		PenWidth:  cmp.Or(over.PenWidth, s.PenWidth),
+		Style:     cmp.Or(over.Style, s.Style),
+		ArrowHead: cmp.Or(over.ArrowHead, s.ArrowHead),
+		FontName:  cmp.Or(over.FontName, s.FontName),
+		FontColor: cmp.Or(over.FontColor, s.FontColor),
+		FontSize:  cmp.Or(over.FontSize, s.FontSize),
+	}
+}
+
+// attrs returns the non-empty attributes, in DOT syntax.
+func (s EdgeStyle) attrs() string {
+	return dotAttrs(
+		"color", s.Color,
+		"penwidth", s.PenWidth,
+		"style", s.Style,
+		"arrowhead", s.ArrowHead,
+		"fontname", s.FontName,
+		"fontcolor", s.FontColor,
+		"fontsize", s.FontSize)
+}
+
+// dotAttrs returns the pairs name, value with a non-empty value, in DOT
+// syntax (name="value"), separated by spaces.
+func dotAttrs(pairs ...string) string {
+	var attrs []string
+	for i := 0; i < len(pairs); i += 2 {
+		if pairs[i+1] != "" {
+			attrs = append(attrs, fmt.Sprintf("%s=%q", pairs[i], pairs[i+1]))
+		}
+	}
+	return strings.Join(attrs, " ")
+}
+
+// nodeRule is a style of the theme that applies to a node, with a
+// description for the legend.
+type nodeRule struct {
+	label string
+	style NodeStyle
+}
+
+// edgeRule is a style of the theme that applies to an edge, with a
+// description for the legend.
+type edgeRule struct {
+	label string
+	style EdgeStyle
+}
+
+// categoryLabels are the descriptions of the status categories, by key.
+var categoryLabels = map[string]string{
+	"new":           "to do",
+	"indeterminate": "in progress",
+	"done":          "done",
+}
+
+// nodeStyle returns the style of 'node', without the defaults of t.Node.
+func (t *Theme) nodeStyle(node *Node) NodeStyle {
+	var style NodeStyle
+	for _, rule := range t.nodeRules(node) {
+		style = style.merge(rule.style)
+	}
+	return style
+}
+
+// nodeRules returns the styles that apply to 'node', in the order they are
+// applied.
+func (t *Theme) nodeRules(node *Node) []nodeRule {
+	if node.Placeholder {
+		return []nodeRule{{"not in the search result", t.Placeholder}}
+	}
+	fields := node.Issue.Fields
+	category := fields.Status.StatusCategory.Key
+	if category == "" {
+		// For example from an old snapshot.
+		category = guessedCategories[strings.ToLower(fields.Status.Name)]
+	}
+	var rules []nodeRule
+	for _, match := range []struct {
+		styles map[string]NodeStyle
+		key    string
+		label  func(key string) string
+	}{
+		{t.StatusCategory, category,
+			func(key string) string { return cmp.Or(categoryLabels[key], key) }},
+		{t.Status, fields.Status.Name,
+			func(key string) string { return "status " + key }},
+		{t.IssueType, fields.IssueType.Name,
+			func(key string) string { return "issue type " + key }},
+		{t.Priority, fields.Priority.Name,
+			func(key string) string { return "priority " + key }},
+	} {
+		for _, key := range slices.Sorted(maps.Keys(match.styles)) {
+			if strings.EqualFold(key, match.key) {
+				rules = append(rules, nodeRule{match.label(key), match.styles[key]})
+			}
+		}
+	}
+	if node.External {
+		rules = append(rules, nodeRule{"fetched by following the links", t.External})
+	}
+	return rules
+}
+
+// guessedCategories are the status categories of the issues without one,
+// by lower-case name of the default statuses.
+var guessedCategories = map[string]string{
+	"to do":       "new",
+	"in progress": "indeterminate",
+	"done":        "done",
+}
+
+// stateStyle returns the style of the dependency state 'state'.
+func (t *Theme) stateStyle(state depState) NodeStyle {
+	var style NodeStyle
+	for _, rule := range t.stateRules(state) {
+		style = style.merge(rule.style)
+	}
+	return style
+}
+
+// stateRules returns the styles that apply to the dependency state 'state',
+// in the order they are applied.
+func (t *Theme) stateRules(state depState) []nodeRule {
+	var rules []nodeRule
+	if state&stateBlocked != 0 {
+		rules = append(rules, nodeRule{"blocked by an unfinished ticket", t.Blocked})
+	}
+	if state&stateReady != 0 {
+		rules = append(rules, nodeRule{"ready: not blocked", t.Ready})
+	}
+	if state&stateBlocking != 0 {
+		rules = append(rules, nodeRule{"blocking an unfinished ticket", t.Blocking})
+	}
+	return rules
+}
+
+// edgeStyle returns the style of 'edge', without the defaults of t.Edge.
+func (t *Theme) edgeStyle(edge *Edge) EdgeStyle {
+	var style EdgeStyle
+	for _, rule := range t.edgeRules(edge) {
+		style = style.merge(rule.style)
+	}
+	return style
+}
+
+// edgeRules returns the styles of the link types that apply to 'edge', in
+// the order they are applied.
+func (t *Theme) edgeRules(edge *Edge) []edgeRule {
+	var rules []edgeRule
+	for _, key := range slices.Sorted(maps.Keys(t.LinkType)) {
+		if matchLinkType(edge.Type, []string{key}) {
+			rules = append(rules, edgeRule{key, t.LinkType[key]})
+		}
+	}
+	return rules
+}
+
+// themes are the built-in themes.
+var themes = map[string]func() *Theme{
+	"default":    defaultTheme,
+	"colorblind": colorblindTheme,
+}
+
+// defaultTheme returns the default theme.
+func defaultTheme() *Theme {
+	return &Theme{
+		Node: NodeStyle{
+			FillColor: "gray",
+			Shape:     "box",
+			Style:     "filled",
+			Width:     "3.5",
+			Height:    "0.5",
+			FixedSize: "true",
+		},
+		Edge: EdgeStyle{Color: "black"},
+		// Any workflow has statuses in these categories.
+		StatusCategory: map[string]NodeStyle{
+			"new":           {FillColor: "cadetblue1"},
+			"indeterminate": {FillColor: "orange"},
+			"done":          {FillColor: "yellowgreen"},
+		},
+		LinkType: map[string]EdgeStyle{
+			"blocks":     {Color: "red"},
+			"relates":    {Style: "dashed"},
+			"duplicates": {Style: "dotted"},
+		},
+		Placeholder: NodeStyle{FillColor: "white", Style: "filled,dashed"},
+		External:    NodeStyle{Color: "purple", PenWidth: "3"},
+		Cycle:       EdgeStyle{Color: "magenta", PenWidth: "3"},
+		Critical:    EdgeStyle{PenWidth: "6"},
+		Contracted:  EdgeStyle{ArrowHead: "empty", FontColor: "gray40"},
+		Blocked:     NodeStyle{Color: "red", PenWidth: "2"},
+		Blocking:    NodeStyle{PenWidth: "4"},
+		Ready:       NodeStyle{Color: "green3", PenWidth: "2"},
+	}
+}
+
+// colorblindTheme returns a theme safe for the common forms of color
+// blindness, based on the Okabe-Ito palette. Where possible, it also uses
+// shapes and line styles, so that it does not rely on colors alone.
+func colorblindTheme() *Theme {
+	const (
+		orange    = "#E69F00"
+		skyBlue   = "#56B4E9"
+		green     = "#009E73"
+		blue      = "#0072B2"
+		vermilion = "#D55E00"
+		purple    = "#CC79A7"
+		gray      = "#BBBBBB"
+	)
+	toDo := NodeStyle{FillColor: skyBlue}
+	inProgress := NodeStyle{FillColor: orange, PenWidth: "2"}
+	done := NodeStyle{FillColor: green, Style: "filled,rounded", FontColor: "white"}
+	return &Theme{
+		Node: NodeStyle{
+			FillColor: gray,
+			Shape:     "box",
+			Style:     "filled",
+			Width:     "3.5",
+			Height:    "0.5",
+			FixedSize: "true",
+		},
+		Edge: EdgeStyle{Color: "black"},
+		StatusCategory: map[string]NodeStyle{
+			"new":           toDo,
+			"indeterminate": inProgress,
+			"done":          done,
+		},
+		LinkType: map[string]EdgeStyle{
+			"blocks":     {Color: vermilion, PenWidth: "2"},
+			"relates":    {Style: "dashed"},
+			"duplicates": {Style: "dotted"},
+		},
+		Placeholder: NodeStyle{FillColor: "white", Style: "filled,dashed"},
+		External:    NodeStyle{Color: blue, PenWidth: "3"},
+		Cycle:       EdgeStyle{Color: purple, PenWidth: "3", Style: "bold"},
+		Critical:    EdgeStyle{PenWidth: "6"},
+		Contracted:  EdgeStyle{ArrowHead: "empty", FontColor: gray},
+		Blocked:     NodeStyle{Color: vermilion, PenWidth: "2", Shape: "octagon"},
+		Blocking:    NodeStyle{PenWidth: "4"},
+		Ready:       NodeStyle{Color: green, PenWidth: "2"},
+	}
+}
+
+// loadTheme returns the built-in theme 'name' (default if empty), or, if
+// 'name' is not a built-in theme, the theme in file 'name'. A relative path
+// is relative to 'dir'. The theme file is applied on top of the default
+// theme, so it needs to contain only what it changes: each style is merged
+// attribute by attribute over the default one, and map keys are lower-cased,
+// so that "To Do" changes the default "to do".
+func loadTheme(name string, dir string) (*Theme, error) {
+	if name == "" {
+		return defaultTheme(), nil
+	}
+	if builtin, found := themes[name]; found {
+		return builtin(), nil
+	}
+
+	path := name
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("theme: %s", err)
+	}
+	theme := defaultTheme()
+	// Decoding into a map would replace a whole default entry: decode the
+	// maps from scratch and merge them afterwards.
+	defaults := *theme
+	theme.StatusCategory, theme.Status, theme.IssueType, theme.Priority = nil, nil, nil, nil
+	theme.LinkType = nil
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(theme); err != nil {
+		return nil, fmt.Errorf("theme %s: %s", path, err)
+	}
+	theme.StatusCategory = mergeStyles(defaults.StatusCategory, theme.StatusCategory)
+	theme.Status = mergeStyles(defaults.Status, theme.Status)
+	theme.IssueType = mergeStyles(defaults.IssueType, theme.IssueType)
+	theme.Priority = mergeStyles(defaults.Priority, theme.Priority)
+	theme.LinkType = mergeStyles(defaults.LinkType, theme.LinkType)
+	return theme, nil
+}
+
+// mergeStyles returns the styles of 'base' and 'over', with lower-case keys.
+// A style of 'over' is merged over the style of 'base' with the same key.
+func mergeStyles[S interface{ merge(S) S }](base, over map[string]S) map[string]S {
+	merged := make(map[string]S, len(base)+len(over))
+	for _, styles := range []map[string]S{base, over} {
+		for _, key := range slices.Sorted(maps.Keys(styles)) {
+			lower := strings.ToLower(key)
+			merged[lower] = merged[lower].merge(styles[key])
+		}
+	}
+	return merged
+}
@@ -0,0 +1,169 @@
+package towel
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/marco-m/rosina"
+)
+
+func TestThemeNodeStyle(t *testing.T) {
+	type testCase struct {
+		name string
+		node *Node
+		want string
+	}
+
+	theme := &Theme{
+		StatusCategory: map[string]NodeStyle{"done": {FillColor: "green", Shape: "box"}},
+		Status:         map[string]NodeStyle{"Won't Do": {FillColor: "gray"}},
+		IssueType:      map[string]NodeStyle{"epic": {Shape: "folder"}},
+		Priority:       map[string]NodeStyle{"highest": {Color: "red", PenWidth: "2"}},
+		Placeholder:    NodeStyle{Style: "dashed"},
+		External:       NodeStyle{Color: "purple"},
+	}
+	ticket := func(category, status, issueType, priority string) Issue {
+		ticket := newIssue("A-1", status)
+		ticket.Fields.Status.StatusCategory.Key = category
+		ticket.Fields.IssueType.Name = issueType
+		ticket.Fields.Priority.Name = priority
+		return ticket
+	}
+
+	testCases := []testCase{
+		{
+			name: "no match",
+			node: &Node{Issue: ticket("new", "To Do", "Story", "Low")},
+			want: ``,
+		},
+		{
+			name: "status category",
+			node: &Node{Issue: ticket("done", "Done", "Story", "Low")},
+			want: `fillcolor="green" shape="box"`,
+		},
+		{
+			name: "status overrides category, case-insensitive",
+			node: &Node{Issue: ticket("done", "won't do", "Story", "Low")},
+			want: `fillcolor="gray" shape="box"`,
+		},
+		{
+			name: "all together",
+			node: &Node{Issue: ticket("done", "Done", "Epic", "Highest"), External: true},
+			want: `fillcolor="green" color="purple" penwidth="2" shape="folder"`,
+		},
+		{
+			name: "placeholder",
+			node: &Node{Issue: ticket("done", "Done", "Epic", "Highest"), Placeholder: true},
+			want: `style="dashed"`,
+		},
+	}
+
+	test := func(t *testing.T, tc testCase) {
+		have := theme.nodeStyle(tc.node).attrs()
+
+		rosina.AssertEqual(t, have, tc.want, "attrs")
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) { test(t, tc) })
+	}
+}
+
+func TestThemeEdgeStyle(t *testing.T) {
+	theme := &Theme{LinkType: map[string]EdgeStyle{
+		"Blocks":     {Color: "red"},
+		"relates to": {Style: "dashed"},
+	}}
+	relates := LinkType{Name: "Relates", Inward: "relates to", Outward: "relates to"}
+
+	rosina.AssertEqual(t, theme.edgeStyle(&Edge{Type: blocks}).attrs(), `color="red"`,
+		"blocks")
+	rosina.AssertEqual(t, theme.edgeStyle(&Edge{Type: relates}).attrs(),
+		`style="dashed"`, "relates")
+	rosina.AssertEqual(t, theme.edgeStyle(&Edge{Type: LinkType{Name: "Clones"}}).attrs(),
+		``, "no match")
+}
+
+func TestLoadTheme(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "theme.json")
+	err := os.WriteFile(file, []byte(`{
+		"node": {"fontname": "Helvetica"},
+		"status": {"blocked": {"fillcolor": "red"}}
+	}`), 0o600)
+	rosina.AssertNoError(t, err)
+
+	theme, err := loadTheme("theme.json", dir)
+	rosina.AssertNoError(t, err)
+
+	rosina.AssertEqual(t, theme.Node.FontName, "Helvetica", "node font")
+	rosina.AssertEqual(t, theme.Status["blocked"].FillColor, "red", "added status")
+	rosina.AssertEqual(t, theme.StatusCategory["new"].FillColor, "cadetblue1",
+		"status category from the default theme")
+	rosina.AssertEqual(t, theme.LinkType["blocks"].Color, "red",
+		"link type from the default theme")
+
+	err = os.WriteFile(file, []byte(`{
+		"status": {"To Do": {"fillcolor": "white"}},
+		"linkType": {"Blocks": {"penwidth": "2"}}
+	}`), 0o600)
+	rosina.AssertNoError(t, err)
+	theme, err = loadTheme("theme.json", dir)
+	rosina.AssertNoError(t, err)
+	rosina.AssertEqual(t, len(theme.Status), 1, "keys merged case-insensitively")
+	rosina.AssertEqual(t, theme.nodeStyle(&Node{Issue: newIssue("A-1", "To Do")}).attrs(),
+		`fillcolor="white"`, "user status overrides the default one")
+	rosina.AssertEqual(t, theme.LinkType["blocks"].attrs(), `color="red" penwidth="2"`,
+		"user link type merged over the default one")
+
+	err = os.WriteFile(file, []byte(`{"statusCategory": {"done": {"fillcolor": "gray80"}}}`), 0o600)
+	rosina.AssertNoError(t, err)
+	theme, err = loadTheme("theme.json", dir)
+	rosina.AssertNoError(t, err)
+	done := newIssue("A-1", "Done")
+	rosina.AssertEqual(t, theme.nodeStyle(&Node{Issue: done}).FillColor, "gray80",
+		"user status category, guessed from the status")
+	done.Fields.Status.StatusCategory.Key = "done"
+	rosina.AssertEqual(t, theme.nodeStyle(&Node{Issue: done}).FillColor, "gray80",
+		"user status category")
+
+	theme, err = loadTheme("colorblind", dir)
+	rosina.AssertNoError(t, err)
+	rosina.AssertEqual(t, theme.LinkType["blocks"].Color, "#D55E00", "built-in theme")
+
+	err = os.WriteFile(file, []byte(`{"nod": {}}`), 0o600)
+	rosina.AssertNoError(t, err)
+	_, err = loadTheme(file, "/nonexistent")
+	rosina.AssertEqual(t, err != nil && strings.Contains(err.Error(), `unknown field "nod"`),
+		true, "typo in the theme file")
+}
+
+func TestDefaultThemeColorsByStatusCategory(t *testing.T) {
+	type testCase struct {
+		status   string
+		category string
+		want     string
+	}
+
+	testCases := []testCase{
+		{status: "Ready for QA", category: "new", want: "cadetblue1"},
+		{status: "Blocked", category: "indeterminate", want: "orange"},
+		{status: "Won't Do", category: "done", want: "yellowgreen"},
+		{status: "In Progress", category: "", want: "orange"},
+		{status: "Blocked", category: "", want: ""},
+	}
+
+	theme := defaultTheme()
+	for _, tc := range testCases {
+		t.Run(tc.status+"/"+tc.category, func(t *testing.T) {
+			ticket := newIssue("A-1", tc.status)
+			ticket.Fields.Status.StatusCategory.Key = tc.category
+
+			have := theme.nodeStyle(&Node{Issue: ticket}).FillColor
+
+			rosina.AssertEqual(t, have, tc.want, "fill color")
+		})
+	}
+}
@@ -3,6 +3,7 @@ package towel
 import (
 	"fmt"
 	"net/http"
+	"os"
 	"time"
 
 	"github.com/marco-m/clim"
@@ -18,7 +19,7 @@ type App struct {
 }
 
 func MainErr(args []string) error {
-	defaultConfigDir, err := defaultConfigDir()
+	defaultConfigDir, err := DefaultConfigDir()
 	if err != nil {
 		return fmt.Errorf("user configuration directory: %w", err)
 	}
@@ -52,7 +53,11 @@ func MainErr(args []string) error {
 	cli.AddCLI(newInitCLI())
 	cli.AddCLI(newGraphCLI())
 	cli.AddCLI(newQueryCLI())
-	cli.AddCLI(newDotCLI())
+	args, snapshots := diffArgs(args)
+	cli.AddCLI(newDiffCLI(snapshots))
+	cli.AddCLI(newCyclesCLI())
+	cli.AddCLI(newCriticalPathCLI())
+	cli.AddCLI(newOrderCLI())
 	cli.AddCLI(versionCmd)
 
 	action, err := cli.Parse(args)
@@ -62,3 +67,13 @@ func MainErr(args []string) error {
 
 	return action(app)
 }
+
+// newClient returns a Client for the Jira instance in the configuration file.
+func (app App) newClient() (*Client, error) {
+	config, err := LoadConfig(app.ConfigDir)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(config,
+		WithHTTPClient(app.HttpClient), WithProgress(os.Stderr)), nil
+}